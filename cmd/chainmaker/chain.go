@@ -0,0 +1,335 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// chainOptions configures the chain built by makeChain.
+type chainOptions struct {
+	blocks     int               // Number of blocks to generate
+	calldatas  [][]byte          // Transaction data, used round-robin per block
+	recipients []*common.Address // Transaction recipients, used round-robin per block, nil creates a contract
+	gasLimits  []uint64          // Transaction gas limits, used round-robin per block
+
+	contracts core.GenesisAlloc // Additional genesis accounts, e.g. from -contracts
+
+	coinbase  common.Address   // Genesis coinbase
+	coinbases []common.Address // Block coinbases, used round-robin per block
+	extra     []byte           // Genesis extra data
+
+	ttd    *big.Int // Terminal total difficulty, nil for a pre-merge chain
+	uncles int      // Uncles included in each proof-of-work block from block 3 on
+
+	realSeal bool // Seal proof-of-work blocks with a valid nonce and mix digest
+	noReward bool // Don't pay block and uncle rewards, see noRewardEngine
+
+	corruptBlock uint64 // Number of the block to corrupt, zero for none
+	corruptField string // Field to corrupt, see corruptBlock
+}
+
+// makeChain builds a genesis and chain where key's account sends one
+// transaction per block, to aa unless other recipients are given.
+//
+// By default blocks aren't sealed, so they only import into clients verifying
+// with fake proof-of-work, as the challenge checkers do. With realSeal, every
+// proof-of-work block is mined with ethash and imports into any client.
+func makeChain(key *ecdsa.PrivateKey, opts *chainOptions) (*core.Genesis, []*types.Block, error) {
+	// Idea:
+	// * programatically define genesis file
+	// * write genesis file
+	// * sketch out chain maker that can be edited on-demand
+	// * write chain to rlp file for import in client
+
+	var (
+		config  = *params.TestChainConfig
+		engine  = consensus.Engine(ethash.NewFaker())
+		gendb   = rawdb.NewMemoryDatabase()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		aa      = common.Address{0xaa}
+		funds   = big.NewInt(1000000000000000)
+		alloc   = core.GenesisAlloc{
+			address: {Balance: funds},
+			aa: {
+				Balance: common.Big0,
+				Nonce:   1,
+				Code: []byte{
+					byte(vm.PUSH1),
+					0x41,
+					byte(vm.PUSH1),
+					0x01,
+					byte(vm.ADD),
+				},
+			},
+		}
+		gspec = &core.Genesis{
+			Config:     &config,
+			Alloc:      alloc,
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: big.NewInt(1234),
+			Coinbase:   opts.coinbase,
+			ExtraData:  opts.extra,
+		}
+	)
+	for addr, account := range opts.contracts {
+		if _, ok := alloc[addr]; ok {
+			return nil, nil, fmt.Errorf("contract at %s collides with a built-in account", addr)
+		}
+		alloc[addr] = account
+	}
+	if err := validateExtra(gspec.Config, gspec.ExtraData); err != nil {
+		return nil, nil, err
+	}
+	genesis := gspec.MustCommit(gendb)
+
+	// Extend the chain past the terminal total difficulty if requested, so it
+	// contains the transition block and at least one proof-of-stake block.
+	n := opts.blocks
+	if opts.realSeal {
+		engine = realEthash()
+	}
+	if opts.noReward {
+		engine = &noRewardEngine{engine}
+	}
+	if opts.ttd != nil {
+		config.TerminalTotalDifficulty = opts.ttd
+		engine = beacon.New(engine)
+		if pow := powBlocks(&config, genesis.Header(), opts.ttd); n < pow+1 {
+			n = pow + 1
+		}
+	}
+	if opts.realSeal {
+		engine = &sealingEngine{engine}
+	}
+	// The chain maker panics on transactions that can't be included, so reject
+	// gas limits outside of what the block and the transaction allow upfront.
+	for i := 0; i < n; i++ {
+		data, to, gas := opts.txFields(i, &aa)
+		if gas > genesis.GasLimit() {
+			return nil, nil, fmt.Errorf("gas of transaction in block %d exceeds block gas limit (have %d, max %d)", i+1, gas, genesis.GasLimit())
+		}
+		intrinsic, err := core.IntrinsicGas(data, nil, to == nil, true, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if gas < intrinsic {
+			return nil, nil, fmt.Errorf("gas of transaction in block %d below intrinsic gas (have %d, want at least %d)", i+1, gas, intrinsic)
+		}
+	}
+	var (
+		td       = new(big.Int).Set(genesis.Difficulty())
+		uncleErr error
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, gendb, n, func(i int, block *core.BlockGen) {
+		// The chain maker has no access to total difficulties, so switch to the
+		// merge rules manually once the parent reached the ttd.
+		if i > 0 {
+			td.Add(td, block.PrevBlock(i-1).Difficulty())
+		}
+		if len(opts.coinbases) > 0 {
+			block.SetCoinbase(opts.coinbases[i%len(opts.coinbases)])
+		}
+		if opts.ttd != nil && td.Cmp(opts.ttd) >= 0 {
+			block.SetDifficulty(common.Big0)
+		} else if i >= 2 {
+			for j := 0; j < opts.uncles && uncleErr == nil; j++ {
+				uncleErr = addUncle(block, engine, block.PrevBlock(i-2), j, opts.realSeal)
+			}
+		}
+		data, to, gas := opts.txFields(i, &aa)
+		tx := types.NewTx(&types.LegacyTx{
+			Nonce:    block.TxNonce(address),
+			To:       to,
+			Value:    big.NewInt(0),
+			Gas:      gas,
+			GasPrice: block.BaseFee(),
+			Data:     data,
+		})
+		x, _ := types.SignTx(tx, types.HomesteadSigner{}, key)
+		block.AddTx(x)
+	})
+	if uncleErr != nil {
+		return nil, nil, uncleErr
+	}
+	if opts.corruptBlock != 0 {
+		if opts.corruptBlock > uint64(len(blocks)) {
+			return nil, nil, fmt.Errorf("can't corrupt block %d, chain has %d blocks", opts.corruptBlock, len(blocks))
+		}
+		bad, err := corruptBlock(blocks[opts.corruptBlock-1], opts.corruptField)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks[opts.corruptBlock-1] = bad
+
+		// Relink the descendants, so the import fails on the corrupted field
+		// rather than on a gap in the chain.
+		for i := int(opts.corruptBlock); i < len(blocks); i++ {
+			header := blocks[i].Header()
+			header.ParentHash = blocks[i-1].Hash()
+			blocks[i] = blocks[i].WithSeal(header)
+		}
+	}
+	return gspec, blocks, nil
+}
+
+// txFields returns the data, recipient and gas limit of the transaction in the
+// i-th generated block. Without recipients, transactions are sent to def.
+func (opts *chainOptions) txFields(i int, def *common.Address) (data []byte, to *common.Address, gas uint64) {
+	to, gas = def, 100000
+	if len(opts.calldatas) > 0 {
+		data = opts.calldatas[i%len(opts.calldatas)]
+	}
+	if len(opts.recipients) > 0 {
+		to = opts.recipients[i%len(opts.recipients)]
+	}
+	if len(opts.gasLimits) > 0 {
+		gas = opts.gasLimits[i%len(opts.gasLimits)]
+	}
+	return data, to, gas
+}
+
+// addUncle adds the j-th sibling of the block's parent as an uncle. Siblings
+// differ only in their extra data, which keeps their hashes distinct.
+func addUncle(block *core.BlockGen, engine consensus.Engine, grandparent *types.Block, j int, seal bool) error {
+	uncle := &types.Header{
+		ParentHash:  grandparent.Hash(),
+		UncleHash:   types.EmptyUncleHash,
+		Root:        grandparent.Root(),
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Number:      new(big.Int).Add(grandparent.Number(), common.Big1),
+		Extra:       []byte{byte(j)},
+	}
+	// The chain maker fills in the time and difficulty of the uncle, so it
+	// can only be sealed once added. The block keeps a reference to the
+	// header, so replace it in place.
+	block.AddUncle(uncle)
+	if !seal {
+		return nil
+	}
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(nil, types.NewBlockWithHeader(uncle), results, nil); err != nil {
+		return fmt.Errorf("couldn't seal uncle: %v", err)
+	}
+	*uncle = *(<-results).Header()
+	return nil
+}
+
+var (
+	ethashOnce sync.Once
+	ethashReal *ethash.Ethash
+)
+
+// realEthash returns a shared full ethash engine, so that the mining dataset is
+// only generated once even when building many chains.
+func realEthash() *ethash.Ethash {
+	ethashOnce.Do(func() {
+		ethashReal = ethash.New(ethash.Config{
+			PowMode:        ethash.ModeNormal,
+			CachesInMem:    1,
+			DatasetDir:     filepath.Join(os.TempDir(), "chainmaker-ethash"),
+			DatasetsInMem:  1,
+			DatasetsOnDisk: 1,
+		}, nil, false)
+	})
+	return ethashReal
+}
+
+// sealingEngine seals proof-of-work blocks as soon as they are assembled. The
+// chain maker uses each assembled block as the parent of the next one, so this
+// keeps the sealed chain linked.
+type sealingEngine struct {
+	consensus.Engine
+}
+
+func (e *sealingEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	block, err := e.Engine.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+	if err != nil || block.Difficulty().Sign() == 0 {
+		return block, err
+	}
+	results := make(chan *types.Block, 1)
+	if err := e.Engine.Seal(chain, block, results, nil); err != nil {
+		return nil, err
+	}
+	return <-results, nil
+}
+
+// noRewardEngine finalizes proof-of-work blocks without paying block or uncle
+// rewards, so coinbase balances only change by transaction fees.
+//
+// The rewards are part of the state root, so such chains are rejected by clients
+// paying the standard rewards. This includes runGeth in the challenge checkers,
+// which has no option to skip them: grading such a chain needs a custom checker
+// importing it with this engine. Proof-of-stake blocks never pay rewards, so a
+// chain past the merge is affected only up to the transition.
+type noRewardEngine struct {
+	consensus.Engine
+}
+
+func (e *noRewardEngine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+}
+
+func (e *noRewardEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	e.Finalize(chain, header, state, txs, uncles)
+	return types.NewBlock(header, txs, uncles, receipts, trie.NewStackTrie(nil)), nil
+}
+
+// powBlocks returns the number of proof-of-work blocks needed after parent for
+// the total difficulty to reach ttd, assuming the chain maker's fixed 10 second
+// block time and no uncles. Uncles only raise the difficulty, so with uncles
+// the estimate is an upper bound.
+func powBlocks(config *params.ChainConfig, parent *types.Header, ttd *big.Int) int {
+	var (
+		td = new(big.Int).Set(parent.Difficulty)
+		n  int
+	)
+	for ; td.Cmp(ttd) < 0; n++ {
+		header := &types.Header{
+			Number:    new(big.Int).Add(parent.Number, common.Big1),
+			Time:      parent.Time + 10,
+			UncleHash: types.EmptyUncleHash,
+		}
+		header.Difficulty = ethash.CalcDifficulty(config, header.Time, parent)
+		td.Add(td, header.Difficulty)
+		parent = header
+	}
+	return n
+}
+
+// validateExtra checks the extra data fits the constraints of the chain's
+// consensus engine. Clique expects a 32 byte vanity, followed by the initial
+// signers' addresses and a 65 byte seal. Ethash allows at most 32 bytes.
+func validateExtra(config *params.ChainConfig, extra []byte) error {
+	if config.Clique != nil {
+		const vanity, seal = 32, crypto.SignatureLength
+		if len(extra) < vanity+seal {
+			return fmt.Errorf("clique extradata too short: have %d bytes, want at least %d", len(extra), vanity+seal)
+		}
+		if signers := len(extra) - vanity - seal; signers%common.AddressLength != 0 {
+			return fmt.Errorf("clique extradata signer section of %d bytes isn't a multiple of %d", signers, common.AddressLength)
+		}
+		return nil
+	}
+	if uint64(len(extra)) > params.MaximumExtraDataSize {
+		return fmt.Errorf("extradata too long: have %d bytes, max %d", len(extra), params.MaximumExtraDataSize)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestNoReward checks the coinbase balance after importing a chain with and
+// without block rewards. Transactions pay no tip, so without rewards the
+// coinbase doesn't receive anything.
+func TestNoReward(t *testing.T) {
+	const n = 5
+	var (
+		key, _   = crypto.GenerateKey()
+		coinbase = common.Address{0xcb}
+	)
+	for _, tt := range []struct {
+		noReward bool
+		want     *big.Int
+	}{
+		{noReward: false, want: new(big.Int).Mul(big.NewInt(n), ethash.ConstantinopleBlockReward)},
+		{noReward: true, want: new(big.Int)},
+	} {
+		opts := &chainOptions{blocks: n, coinbases: []common.Address{coinbase}, noReward: tt.noReward}
+		gspec, blocks, err := makeChain(key, opts)
+		if err != nil {
+			t.Fatalf("noReward=%v: couldn't make chain: %v", tt.noReward, err)
+		}
+		// Import with the engine the chain was made with, the standard one
+		// rejects chains without rewards.
+		engine := consensus.Engine(ethash.NewFaker())
+		if tt.noReward {
+			engine = &noRewardEngine{engine}
+		}
+		db := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(db)
+		chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("noReward=%v: couldn't import chain: %v", tt.noReward, err)
+		}
+		state, err := chain.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := state.GetBalance(coinbase); have.Cmp(tt.want) != 0 {
+			t.Errorf("noReward=%v: wrong coinbase balance after %d blocks (have %v, want %v)", tt.noReward, n, have, tt.want)
+		}
+		chain.Stop()
+	}
+}
+
+// importChain imports the chain into a fresh blockchain using engine, returning
+// the index of the first failing block on error.
+func importChain(t *testing.T, gspec *core.Genesis, blocks []*types.Block, engine consensus.Engine) (*core.BlockChain, int, error) {
+	t.Helper()
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(chain.Stop)
+	n, err := chain.InsertChain(blocks)
+	return chain, n, err
+}
+
+// TestMerge checks that a chain with a terminal total difficulty imports with
+// the beacon engine, and switches to proof-of-stake right after the first block
+// reaching it.
+func TestMerge(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	ttd := big.NewInt(3 * 131072)
+	gspec, blocks, err := makeChain(key, &chainOptions{blocks: 1, ttd: ttd})
+	if err != nil {
+		t.Fatalf("couldn't make chain: %v", err)
+	}
+	chain, _, err := importChain(t, gspec, blocks, beacon.New(ethash.NewFaker()))
+	if err != nil {
+		t.Fatalf("couldn't import chain: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != uint64(len(blocks)) {
+		t.Fatalf("wrong head after import (have %d, want %d)", head, len(blocks))
+	}
+	// Find the first proof-of-stake block, its parent is the terminal block.
+	var switchBlock *types.Block
+	for _, block := range blocks {
+		if block.Difficulty().Sign() == 0 {
+			switchBlock = block
+			break
+		}
+	}
+	if switchBlock == nil {
+		t.Fatalf("no proof-of-stake block in chain of %d blocks", len(blocks))
+	}
+	terminal := chain.GetBlockByHash(switchBlock.ParentHash())
+	if td := chain.GetTd(terminal.Hash(), terminal.NumberU64()); td.Cmp(ttd) < 0 {
+		t.Errorf("parent of switch block %d below ttd (have %v, want at least %v)", switchBlock.NumberU64(), td, ttd)
+	}
+	if terminal.Difficulty().Sign() == 0 {
+		t.Fatalf("terminal block %d has no difficulty", terminal.NumberU64())
+	}
+	parent := chain.GetBlockByHash(terminal.ParentHash())
+	if td := chain.GetTd(parent.Hash(), parent.NumberU64()); td.Cmp(ttd) >= 0 {
+		t.Errorf("block %d before terminal block already reached ttd (have %v, want below %v)", parent.NumberU64(), td, ttd)
+	}
+	for _, block := range blocks[switchBlock.NumberU64()-1:] {
+		if block.Difficulty().Sign() != 0 {
+			t.Errorf("block %d after switch block has difficulty %v", block.NumberU64(), block.Difficulty())
+		}
+	}
+}
+
+// TestUncles checks that a chain with uncles imports and pays the uncle rewards.
+// Blocks 3 and 4 include two uncles each, both one block behind, mined by the
+// zero address.
+func TestUncles(t *testing.T) {
+	const n = 4
+	var (
+		key, _   = crypto.GenerateKey()
+		coinbase = common.Address{0xcb}
+		reward   = ethash.ConstantinopleBlockReward
+	)
+	gspec, blocks, err := makeChain(key, &chainOptions{blocks: n, coinbases: []common.Address{coinbase}, uncles: 2})
+	if err != nil {
+		t.Fatalf("couldn't make chain: %v", err)
+	}
+	for _, block := range blocks {
+		want := 0
+		if block.NumberU64() >= 3 {
+			want = 2
+		}
+		if have := len(block.Uncles()); have != want {
+			t.Fatalf("wrong number of uncles in block %d (have %d, want %d)", block.NumberU64(), have, want)
+		}
+	}
+	chain, _, err := importChain(t, gspec, blocks, ethash.NewFaker())
+	if err != nil {
+		t.Fatalf("couldn't import chain: %v", err)
+	}
+	state, err := chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The miner earns a 32nd of the block reward per included uncle, the uncle
+	// miner 7/8ths of it per uncle.
+	var (
+		wantMiner = new(big.Int).Add(new(big.Int).Mul(big.NewInt(n), reward), new(big.Int).Div(new(big.Int).Mul(big.NewInt(4), reward), big.NewInt(32)))
+		wantUncle = new(big.Int).Div(new(big.Int).Mul(big.NewInt(4*7), reward), big.NewInt(8))
+	)
+	if have := state.GetBalance(coinbase); have.Cmp(wantMiner) != 0 {
+		t.Errorf("wrong miner balance (have %v, want %v)", have, wantMiner)
+	}
+	if have := state.GetBalance(common.Address{}); have.Cmp(wantUncle) != 0 {
+		t.Errorf("wrong uncle miner balance (have %v, want %v)", have, wantUncle)
+	}
+}
+
+// TestCorrupt checks that a chain with a corrupted block imports up to the
+// block before it, and fails at it.
+func TestCorrupt(t *testing.T) {
+	const n, bad = 4, 3
+	key, _ := crypto.GenerateKey()
+	for _, field := range corruptFields {
+		gspec, blocks, err := makeChain(key, &chainOptions{blocks: n, corruptBlock: bad, corruptField: field})
+		if err != nil {
+			t.Fatalf("%s: couldn't make chain: %v", field, err)
+		}
+		chain, index, err := importChain(t, gspec, blocks, ethash.NewFaker())
+		if err == nil {
+			t.Errorf("%s: corrupted chain imported", field)
+			continue
+		}
+		if failed := blocks[index].NumberU64(); failed != bad {
+			t.Errorf("%s: import failed at wrong block (have %d, want %d): %v", field, failed, bad, err)
+		}
+		if head := chain.CurrentBlock().NumberU64(); head != bad-1 {
+			t.Errorf("%s: wrong head after failed import (have %d, want %d)", field, head, bad-1)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// artifact is the subset of a compiler artifact needed to install a contract in
+// the genesis alloc. Hardhat writes the bytecode as a string, solc and Foundry
+// as an object.
+type artifact struct {
+	ContractName     string          `json:"contractName"`
+	DeployedBytecode json.RawMessage `json:"deployedBytecode"`
+	StorageLayout    struct {
+		Storage []struct {
+			Label  string `json:"label"`
+			Slot   string `json:"slot"`
+			Offset uint   `json:"offset"`
+		} `json:"storage"`
+	} `json:"storageLayout"`
+}
+
+// code decodes the deployed bytecode of the artifact.
+func (a *artifact) code() ([]byte, error) {
+	var str string
+	if err := json.Unmarshal(a.DeployedBytecode, &str); err != nil {
+		var obj struct {
+			Object string `json:"object"`
+		}
+		if err := json.Unmarshal(a.DeployedBytecode, &obj); err != nil {
+			return nil, fmt.Errorf("invalid deployedBytecode")
+		}
+		str = obj.Object
+	}
+	if str == "" || str == "0x" {
+		return nil, fmt.Errorf("no deployedBytecode")
+	}
+	return parseHex(str)
+}
+
+// contractAddress returns the address a contract is installed at, derived from
+// its name so it stays put when contracts are added or removed.
+func contractAddress(name string) common.Address {
+	return common.BytesToAddress(crypto.Keccak256([]byte(name))[12:])
+}
+
+// loadContracts reads the artifacts (*.json) in dir and returns an alloc with
+// each contract installed at contractAddress of its name, along with the names
+// by address.
+//
+// The initial storage of contract X can be given in X.storage.json, mapping
+// either variable names from the artifact's storageLayout or hex slots to hex
+// values. Variables packed into a slot with others are placed at their offset.
+func loadContracts(dir string) (core.GenesisAlloc, map[common.Address]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		alloc = make(core.GenesisAlloc)
+		names = make(map[common.Address]string)
+	)
+	for _, file := range files {
+		if strings.HasSuffix(file, ".storage.json") {
+			continue
+		}
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		var a artifact
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, nil, fmt.Errorf("invalid artifact %s: %s", file, err)
+		}
+		name := a.ContractName
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		code, err := a.code()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid artifact %s: %s", file, err)
+		}
+		storage, err := loadStorage(strings.TrimSuffix(file, ".json")+".storage.json", &a)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid storage for %s: %s", name, err)
+		}
+		addr := contractAddress(name)
+		if other, ok := names[addr]; ok {
+			return nil, nil, fmt.Errorf("duplicate contract name %q in %s and %s", name, other, file)
+		}
+		alloc[addr] = core.GenesisAccount{Balance: new(big.Int), Code: code, Storage: storage}
+		names[addr] = name
+	}
+	if len(alloc) == 0 {
+		return nil, nil, fmt.Errorf("no artifacts in %s", dir)
+	}
+	return alloc, names, nil
+}
+
+// loadStorage reads the initial storage of a contract, if the file exists.
+func loadStorage(file string, a *artifact) (map[common.Hash]common.Hash, error) {
+	raw, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	storage := make(map[common.Hash]common.Hash)
+	for key, str := range values {
+		value, ok := new(big.Int).SetString(strings.TrimPrefix(str, "0x"), 16)
+		if !ok || value.Sign() < 0 || value.BitLen() > 256 {
+			return nil, fmt.Errorf("invalid value for %s: %q", key, str)
+		}
+		if strings.HasPrefix(key, "0x") {
+			slot, err := parseHex(key)
+			if err != nil || len(slot) > common.HashLength {
+				return nil, fmt.Errorf("invalid slot %q", key)
+			}
+			storage[common.BytesToHash(slot)] = common.BigToHash(value)
+			continue
+		}
+		found := false
+		for _, v := range a.StorageLayout.Storage {
+			if v.Label != key {
+				continue
+			}
+			n, err := strconv.ParseUint(v.Slot, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slot %q for %s", v.Slot, key)
+			}
+			slot := common.BigToHash(new(big.Int).SetUint64(n))
+			packed := storage[slot].Big()
+			packed.Or(packed, value.Lsh(value, v.Offset*8))
+			if packed.BitLen() > 256 {
+				return nil, fmt.Errorf("value for %s overflows its slot", key)
+			}
+			storage[slot] = common.BigToHash(packed)
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown variable %q", key)
+		}
+	}
+	return storage, nil
+}
+
+// printContracts lists the installed contracts by name.
+func printContracts(names map[common.Address]string) {
+	addrs := make([]common.Address, 0, len(names))
+	for addr := range names {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return names[addrs[i]] < names[addrs[j]] })
+	for _, addr := range addrs {
+		fmt.Printf("%s %s\n", addr, names[addr])
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// corruptFields lists the block fields corruptBlock knows how to break.
+var corruptFields = []string{"root", "receipts", "signature"}
+
+// corruptBlock returns a copy of block that fails validation on import:
+//
+//   - root: the header's state root doesn't match the post state
+//   - receipts: the header's receipt root doesn't match the receipts
+//   - signature: the first transaction has an unrecoverable signature, with
+//     the transaction root updated so only the signature is at fault
+//
+// The header hash changes too, which invalidates the seal of real sealed
+// blocks. Chains containing a corrupted block are expected to fail import at
+// that block.
+func corruptBlock(block *types.Block, field string) (*types.Block, error) {
+	header := block.Header()
+	switch field {
+	case "root":
+		header.Root[0] ^= 0xff
+		return block.WithSeal(header), nil
+	case "receipts":
+		header.ReceiptHash[0] ^= 0xff
+		return block.WithSeal(header), nil
+	case "signature":
+		txs := block.Transactions()
+		if len(txs) == 0 {
+			return nil, fmt.Errorf("block %d has no transactions to corrupt", block.NumberU64())
+		}
+		tx, err := txs[0].WithSignature(types.HomesteadSigner{}, make([]byte, crypto.SignatureLength))
+		if err != nil {
+			return nil, err
+		}
+		txs = append(types.Transactions{tx}, txs[1:]...)
+		header.TxHash = types.DeriveSha(txs, trie.NewStackTrie(nil))
+		return types.NewBlockWithHeader(header).WithBody(txs, block.Uncles()), nil
+	default:
+		return nil, fmt.Errorf("unknown corrupt field %q (want one of %v)", field, corruptFields)
+	}
+}
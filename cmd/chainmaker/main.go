@@ -1,86 +1,254 @@
 package main
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"math/big"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/params"
 )
 
 func main() {
 	chainFilename := flag.String("chain", "chain.rlp", "path to write chain file")
+	format := flag.String("format", "rlp", "chain file format: rlp, json (inspection only) or both")
 	genesisFilename := flag.String("genesis", "genesis.json", "path to write genesis file")
+	flagFilename := flag.String("flag", "flag.json", "path to write the flag condition, as graded by checkbundle")
+	numBlocks := flag.Int("blocks", 1, "number of blocks to generate")
+	calldataHex := flag.String("calldata", "", "hex encoded data for the generated transactions")
+	calldataFilename := flag.String("calldata-file", "", "path to JSON list of hex encoded data, used round-robin per block")
+	toList := flag.String("to", "", "comma separated transaction recipients, rotated per block, an empty entry creates a contract from the calldata")
+	gasList := flag.String("gas", "100000", "comma separated transaction gas limits, rotated per block")
+	contractsDir := flag.String("contracts", "", "directory of compiler artifacts (*.json) to install in the genesis alloc, with optional initial storage in <name>.storage.json")
+	coinbaseHex := flag.String("coinbase", "", "address to set as the genesis coinbase")
+	coinbasesList := flag.String("coinbases", "", "comma separated addresses to use as block coinbases, rotated per block")
+	extraHex := flag.String("extradata", "", "hex encoded genesis extra data")
+	ttdStr := flag.String("ttd", "", "terminal total difficulty, extends the chain past the merge transition")
+	numUncles := flag.Int("uncles", 0, "number of uncles to include in each proof-of-work block from block 3 on (max 2)")
+	sealMode := flag.String("seal", "fake", "block sealing mode, fake or real (ethash)")
+	noReward := flag.Bool("no-reward", false, "don't pay proof-of-work block and uncle rewards, the chain then only imports into clients doing the same")
+	corruptNum := flag.Uint64("corrupt-block", 0, "number of a block to corrupt, the chain is then expected to fail import")
+	corruptField := flag.String("corrupt-field", "root", "field of the corrupted block to break: root, receipts or signature")
+	variants := flag.Int("variants", 0, "number of independent challenge variants to generate")
+	outDir := flag.String("out", "variants", "directory to write variants to")
 	flag.Parse()
 
-	// Idea:
-	// * programatically define genesis file
-	// * write genesis file
-	// * sketch out chain maker that can be edited on-demand
-	// * write chain to rlp file for import in client
-
-	var (
-		gendb   = rawdb.NewMemoryDatabase()
-		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
-		address = crypto.PubkeyToAddress(key.PublicKey)
-		aa      = common.Address{0xaa}
-		funds   = big.NewInt(1000000000000000)
-		alloc   = core.GenesisAlloc{
-			address: {Balance: funds},
-			aa: {
-				Balance: common.Big0,
-				Nonce:   1,
-				Code: []byte{
-					byte(vm.PUSH1),
-					0x41,
-					byte(vm.PUSH1),
-					0x01,
-					byte(vm.ADD),
-				},
-			},
+	if *numBlocks < 1 {
+		exit(fmt.Errorf("invalid number of blocks: %d", *numBlocks))
+	}
+	var calldatas [][]byte
+	switch {
+	case *calldataHex != "" && *calldataFilename != "":
+		exit(fmt.Errorf("-calldata and -calldata-file are mutually exclusive"))
+	case *calldataHex != "":
+		data, err := parseHex(*calldataHex)
+		if err != nil {
+			exit(fmt.Errorf("invalid calldata: %s", err))
+		}
+		calldatas = [][]byte{data}
+	case *calldataFilename != "":
+		var err error
+		if calldatas, err = loadCalldatas(*calldataFilename); err != nil {
+			exit(fmt.Errorf("unable to load calldata file: %s", err))
 		}
-		gspec = &core.Genesis{
-			Config:     params.TestChainConfig,
-			Alloc:      alloc,
-			BaseFee:    big.NewInt(params.InitialBaseFee),
-			Difficulty: big.NewInt(1234),
+	}
+	opts := &chainOptions{blocks: *numBlocks, calldatas: calldatas}
+	var contracts map[common.Address]string
+	if *contractsDir != "" {
+		var err error
+		if opts.contracts, contracts, err = loadContracts(*contractsDir); err != nil {
+			exit(fmt.Errorf("unable to load contracts: %s", err))
+		}
+		printContracts(contracts)
+	}
+	if *coinbaseHex != "" {
+		if !common.IsHexAddress(*coinbaseHex) {
+			exit(fmt.Errorf("invalid coinbase address: %q", *coinbaseHex))
+		}
+		opts.coinbase = common.HexToAddress(*coinbaseHex)
+	}
+	if *toList != "" {
+		for _, str := range strings.Split(*toList, ",") {
+			if str == "" {
+				opts.recipients = append(opts.recipients, nil)
+				continue
+			}
+			if !common.IsHexAddress(str) {
+				exit(fmt.Errorf("invalid recipient address: %q", str))
+			}
+			to := common.HexToAddress(str)
+			opts.recipients = append(opts.recipients, &to)
+		}
+	}
+	for _, str := range strings.Split(*gasList, ",") {
+		gas, err := strconv.ParseUint(str, 0, 64)
+		if err != nil {
+			exit(fmt.Errorf("invalid gas limit: %q", str))
 		}
-		genesis = gspec.MustCommit(gendb)
-	)
+		opts.gasLimits = append(opts.gasLimits, gas)
+	}
+	if *coinbasesList != "" {
+		for _, str := range strings.Split(*coinbasesList, ",") {
+			if !common.IsHexAddress(str) {
+				exit(fmt.Errorf("invalid coinbase address: %q", str))
+			}
+			opts.coinbases = append(opts.coinbases, common.HexToAddress(str))
+		}
+	}
+	if *extraHex != "" {
+		extra, err := parseHex(*extraHex)
+		if err != nil {
+			exit(fmt.Errorf("invalid extradata: %s", err))
+		}
+		opts.extra = extra
+	}
+	if *ttdStr != "" {
+		ttd, ok := new(big.Int).SetString(*ttdStr, 0)
+		if !ok || ttd.Sign() < 0 {
+			exit(fmt.Errorf("invalid terminal total difficulty: %q", *ttdStr))
+		}
+		opts.ttd = ttd
+	}
+	if *numUncles < 0 || *numUncles > 2 {
+		exit(fmt.Errorf("invalid number of uncles: %d (max 2)", *numUncles))
+	}
+	opts.uncles = *numUncles
+	switch *sealMode {
+	case "fake":
+	case "real":
+		opts.realSeal = true
+	default:
+		exit(fmt.Errorf("invalid seal mode: %q (want fake or real)", *sealMode))
+	}
+
+	opts.noReward = *noReward
+	opts.corruptBlock, opts.corruptField = *corruptNum, *corruptField
+
+	if *format != "rlp" && *format != "json" && *format != "both" {
+		exit(fmt.Errorf("invalid format: %q (want rlp, json or both)", *format))
+	}
+	if *variants < 0 {
+		exit(fmt.Errorf("invalid number of variants: %d", *variants))
+	}
+	if *variants > 0 && *format != "rlp" {
+		exit(fmt.Errorf("-format %s is not supported with -variants, which always writes rlp", *format))
+	}
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	if *variants > 0 {
+		if err := makeVariants(key, opts, *variants, *outDir); err != nil {
+			exit(err)
+		}
+		fmt.Printf("wrote %d variants to %s", *variants, *outDir)
+		warnSeal(opts)
+		warnReward(opts)
+		warnCorrupt(opts)
+		fmt.Println()
+		return
+	}
 
 	// Build chain.
-	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, 1, func(i int, block *core.BlockGen) {
-		tx := types.NewTransaction(
-			0,
-			aa,
-			big.NewInt(0),
-			100000,
-			block.BaseFee(),
-			nil,
-		)
-		x, _ := types.SignTx(tx, types.HomesteadSigner{}, key)
-		block.AddTx(x)
-	})
+	gspec, blocks, err := makeChain(key, opts)
+	if err != nil {
+		exit(err)
+	}
 
 	// Write to disk.
-	err := writeGenesis(gspec, *genesisFilename)
+	err = writeGenesis(gspec, *genesisFilename)
 	if err != nil {
 		exit(fmt.Errorf("unable to write genesis file: %s", err))
 	}
-	err = writeChain(blocks, *chainFilename)
-	if err != nil {
-		exit(fmt.Errorf("unable to write chain to disk: %s", err))
+	if err := writeFlag(gspec, blocks, *flagFilename); err != nil {
+		exit(fmt.Errorf("unable to write flag file: %s", err))
+	}
+	if *format != "json" {
+		if err := writeChain(blocks, *chainFilename); err != nil {
+			exit(fmt.Errorf("unable to write chain to disk: %s", err))
+		}
+	}
+	if *format != "rlp" {
+		// Write the JSON next to where the RLP would go, e.g. chain.json for
+		// chain.rlp.
+		filename := strings.TrimSuffix(*chainFilename, filepath.Ext(*chainFilename)) + ".json"
+		if err := writeChainJSON(blocks, filename); err != nil {
+			exit(fmt.Errorf("unable to write chain JSON to disk: %s", err))
+		}
 	}
 
 	fmt.Printf("wrote %d blocks to disk", len(blocks))
+	for _, block := range blocks {
+		if block.Difficulty().Sign() == 0 {
+			fmt.Printf(", first proof-of-stake block is %d", block.NumberU64())
+			break
+		}
+	}
+	warnSeal(opts)
+	warnReward(opts)
+	warnCorrupt(opts)
+	fmt.Println()
+}
+
+// warnSeal reminds the user that fake sealed chains only import into clients
+// verifying with fake proof-of-work.
+func warnSeal(opts *chainOptions) {
+	if !opts.realSeal {
+		fmt.Printf("\nwarning: blocks are not sealed, the chain only imports with fake proof-of-work (ethash.ModeFake)")
+	}
+}
+
+// warnReward reminds the user that chains without rewards only import into
+// clients that don't pay them either, which rules out the challenge checkers.
+func warnReward(opts *chainOptions) {
+	if opts.noReward {
+		fmt.Printf("\nwarning: blocks pay no rewards, the chain only imports into clients that don't pay them either, not into the challenge checkers")
+	}
+}
+
+// warnCorrupt reminds the user that a chain with a corrupted block is meant to
+// be rejected.
+func warnCorrupt(opts *chainOptions) {
+	if opts.corruptBlock != 0 {
+		fmt.Printf("\nwarning: corrupted the %s of block %d, the chain is expected to fail import", opts.corruptField, opts.corruptBlock)
+	}
+}
+
+// loadCalldatas reads a JSON list of hex encoded calldatas.
+func loadCalldatas(filename string) ([][]byte, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no calldata entries")
+	}
+	calldatas := make([][]byte, len(list))
+	for i, str := range list {
+		if calldatas[i], err = parseHex(str); err != nil {
+			return nil, fmt.Errorf("invalid calldata at index %d: %s", i, err)
+		}
+	}
+	return calldatas, nil
+}
+
+// parseHex decodes a hex string with an optional 0x prefix.
+func parseHex(str string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(str, "0x"), "0X"))
+	if err != nil {
+		return nil, fmt.Errorf("%q is not valid hex: %s", str, err)
+	}
+	return data, nil
 }
 
 func writeChain(chain []*types.Block, filename string) error {
@@ -95,6 +263,32 @@ func writeChain(chain []*types.Block, filename string) error {
 	return nil
 }
 
+// jsonBlock is the JSON representation of a block written by writeChainJSON.
+type jsonBlock struct {
+	Header       *types.Header      `json:"header"`
+	Transactions types.Transactions `json:"transactions"`
+	Uncles       []*types.Header    `json:"uncles"`
+}
+
+// writeChainJSON writes the chain as a JSON list of blocks, for inspection by
+// humans and tools that don't speak RLP. Clients can't import this format.
+func writeChainJSON(chain []*types.Block, filename string) error {
+	blocks := make([]jsonBlock, len(chain))
+	for i, b := range chain {
+		// Keep empty lists as [] rather than null.
+		blocks[i] = jsonBlock{
+			Header:       b.Header(),
+			Transactions: append(types.Transactions{}, b.Transactions()...),
+			Uncles:       append([]*types.Header{}, b.Uncles()...),
+		}
+	}
+	raw, err := json.MarshalIndent(blocks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, raw, 0644)
+}
+
 func writeGenesis(gspec *core.Genesis, filename string) error {
 	w, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
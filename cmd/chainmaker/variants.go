@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lightclient/protocol-ctf/flags/verify"
+)
+
+// makeVariants generates n independent challenge directories in parallel. Each
+// variant uses its own key, derived deterministically from the base key, so the
+// resulting chains are distinct.
+func makeVariants(base *ecdsa.PrivateKey, opts *chainOptions, n int, dir string) error {
+	var (
+		jobs = make(chan int)
+		errc = make(chan error, n)
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errc <- makeVariant(base, opts, i, dir)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	close(errc)
+
+	for err := range errc {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeVariant generates the i-th variant into its own directory under dir.
+func makeVariant(base *ecdsa.PrivateKey, opts *chainOptions, i int, dir string) error {
+	key, err := variantKey(base, i)
+	if err != nil {
+		return fmt.Errorf("variant %d: unable to derive key: %s", i, err)
+	}
+	gspec, blocks, err := makeChain(key, opts)
+	if err != nil {
+		return fmt.Errorf("variant %d: %s", i, err)
+	}
+
+	out := filepath.Join(dir, fmt.Sprintf("variant-%04d", i))
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("variant %d: %s", i, err)
+	}
+	if err := writeGenesis(gspec, filepath.Join(out, "genesis.json")); err != nil {
+		return fmt.Errorf("variant %d: unable to write genesis file: %s", i, err)
+	}
+	if err := writeChain(blocks, filepath.Join(out, "chain.rlp")); err != nil {
+		return fmt.Errorf("variant %d: unable to write chain to disk: %s", i, err)
+	}
+	if err := writeFlag(gspec, blocks, filepath.Join(out, "flag.json")); err != nil {
+		return fmt.Errorf("variant %d: unable to write flag file: %s", i, err)
+	}
+	return nil
+}
+
+// writeFlag writes the completion criteria of the chain: loading it up to its
+// head, whose only transaction is the one bundles must prove.
+func writeFlag(gspec *core.Genesis, blocks []*types.Block, filename string) error {
+	head := blocks[len(blocks)-1]
+	spec := verify.FlagSpec{
+		Genesis: gspec.ToBlock().Hash(),
+		Number:  head.NumberU64(),
+		Hash:    head.Hash(),
+		Index:   0,
+	}
+	raw, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, raw, 0644)
+}
+
+// variantKey derives the key for the i-th variant from the base key.
+func variantKey(base *ecdsa.PrivateKey, i int) (*ecdsa.PrivateKey, error) {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], uint64(i))
+	return crypto.ToECDSA(crypto.Keccak256(crypto.FromECDSA(base), index[:]))
+}
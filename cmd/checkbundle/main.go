@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lightclient/protocol-ctf/flags/verify"
+)
+
+func main() {
+	bundleFilename := flag.String("bundle", "bundle.json", "path to the bundle submitted by the solver")
+	genesisFilename := flag.String("genesis", "genesis.json", "path to the challenge's genesis file")
+	flagFilename := flag.String("flag", "flag.json", "path to the challenge's flag condition")
+	fakePow := flag.Bool("fakepow", false, "skip seal verification (required for chainmaker chains)")
+	flag.Parse()
+
+	// The bundle is verified entirely in-process, without starting a node or
+	// touching the network. The genesis block is the trusted anchor: the
+	// bundle's headers must form a valid chain descending from it.
+	gen, err := loadGenesis(*genesisFilename)
+	if err != nil {
+		exit(fmt.Errorf("unable to load genesis: %s", err))
+	}
+	spec, err := verify.LoadFlagSpec(*flagFilename)
+	if err != nil {
+		exit(fmt.Errorf("unable to load flag: %s", err))
+	}
+	bundle, err := verify.LoadBundle(*bundleFilename)
+	if err != nil {
+		exit(fmt.Errorf("unable to load bundle: %s", err))
+	}
+	if err := checkFlag(gen, spec, bundle, *fakePow); err != nil {
+		fmt.Fprintf(os.Stderr, "Flag not captured: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Flag captured.")
+}
+
+// checkFlag verifies the bundle against the genesis and grades it against the
+// flag condition: the chain must end at the flag block, and the proven receipt
+// must be the flag transaction's and successful. Headers past the flag block
+// aren't accepted, as a solver could append one committing to any receipt.
+func checkFlag(gen *core.Genesis, spec *verify.FlagSpec, bundle *verify.Bundle, fakePow bool) error {
+	genesis := gen.ToBlock().Header()
+	if genesis.Hash() != spec.Genesis {
+		return fmt.Errorf("wrong genesis (have %x, want %x)", genesis.Hash(), spec.Genesis)
+	}
+	// Headers start at block 1, right after the genesis.
+	if uint64(len(bundle.Headers)) != spec.Number {
+		return fmt.Errorf("bundle doesn't end at block %d (have %d headers)", spec.Number, len(bundle.Headers))
+	}
+	if bundle.Index != spec.Index {
+		return fmt.Errorf("wrong receipt index (have %d, want %d)", bundle.Index, spec.Index)
+	}
+	receipt, headers, err := bundle.Verify(gen.Config, genesis, fakePow)
+	if err != nil {
+		return fmt.Errorf("bundle invalid: %s", err)
+	}
+	if have := headers[len(headers)-1].Hash(); have != spec.Hash {
+		return fmt.Errorf("wrong block %d hash (have %x, want %x)", spec.Number, have, spec.Hash)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.New("receipt status failed")
+	}
+	return nil
+}
+
+func loadGenesis(filename string) (*core.Genesis, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var gen core.Genesis
+	if err := json.Unmarshal(raw, &gen); err != nil {
+		return nil, err
+	}
+	return &gen, nil
+}
+
+func exit(msg error) {
+	fmt.Fprintf(os.Stderr, "%s\n", msg)
+	os.Exit(1)
+}
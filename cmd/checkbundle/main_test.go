@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/lightclient/protocol-ctf/flags/verify"
+)
+
+// makeChain generates n blocks with one transfer each, and returns the flag
+// spec of the last block along with the headers and receipts of all blocks.
+func makeChain(n int) (*core.Genesis, *verify.FlagSpec, []*types.Header, []types.Receipts) {
+	var (
+		key, _ = crypto.GenerateKey()
+		from   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &core.Genesis{
+			Config:     params.TestChainConfig,
+			Alloc:      core.GenesisAlloc{from: {Balance: big.NewInt(1e18)}},
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			Difficulty: big.NewInt(1234),
+		}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSigner(gspec.Config)
+	)
+	blocks, receipts := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		b.AddTx(types.MustSignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    b.TxNonce(from),
+			To:       &common.Address{0xaa},
+			Gas:      21000,
+			GasPrice: b.BaseFee(),
+		}))
+	})
+	headers := make([]*types.Header, len(blocks))
+	for i, b := range blocks {
+		headers[i] = b.Header()
+	}
+	spec := &verify.FlagSpec{
+		Genesis: genesis.Hash(),
+		Number:  uint64(n),
+		Hash:    blocks[n-1].Hash(),
+	}
+	return gspec, spec, headers, receipts
+}
+
+func TestCheckFlag(t *testing.T) {
+	gspec, spec, headers, receipts := makeChain(3)
+	bundle, err := verify.NewBundle(headers, receipts[2], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkFlag(gspec, spec, bundle, true); err != nil {
+		t.Fatalf("valid bundle rejected: %v", err)
+	}
+	// Receipts of earlier blocks don't capture the flag.
+	early, err := verify.NewBundle(headers[:2], receipts[1], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkFlag(gspec, spec, early, true); err == nil {
+		t.Fatal("bundle ending before the flag block accepted")
+	}
+}
+
+// TestCheckFlagForgedHeader checks a bundle appending a header that commits to
+// a made-up receipt after the flag block is rejected, even though the header
+// chain itself is valid.
+func TestCheckFlagForgedHeader(t *testing.T) {
+	gspec, spec, headers, _ := makeChain(4)
+	spec.Number, spec.Hash = 3, headers[2].Hash()
+
+	forged := types.Receipts{{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}}}
+	head := types.CopyHeader(headers[3])
+	head.ReceiptHash = types.DeriveSha(forged, trie.NewStackTrie(nil))
+
+	bundle, err := verify.NewBundle(append(headers[:3:3], head), forged, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bundle.Verify(gspec.Config, gspec.ToBlock().Header(), true); err != nil {
+		t.Fatalf("forged header chain should be valid on its own: %v", err)
+	}
+	if err := checkFlag(gspec, spec, bundle, true); err == nil {
+		t.Fatal("bundle with forged trailing header accepted")
+	}
+}
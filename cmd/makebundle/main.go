@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/lightclient/protocol-ctf/flags/verify"
+)
+
+func main() {
+	rpcURL := flag.String("rpc", "http://127.0.0.1:8545", "RPC endpoint of a client that imported the challenge's chain")
+	flagFilename := flag.String("flag", "flag.json", "path to the challenge's flag condition")
+	bundleFilename := flag.String("out", "bundle.json", "path to write the bundle to")
+	flag.Parse()
+
+	// The bundle proves the receipt of the flag transaction, which only a
+	// client executing the chain correctly can provide.
+	spec, err := verify.LoadFlagSpec(*flagFilename)
+	if err != nil {
+		exit(fmt.Errorf("unable to load flag: %s", err))
+	}
+	eth, err := ethclient.Dial(*rpcURL)
+	if err != nil {
+		exit(fmt.Errorf("unable to connect to client: %s", err))
+	}
+	defer eth.Close()
+
+	ctx := context.Background()
+	bundle, err := verify.FetchBundle(ctx, eth, spec.Number, int(spec.Index))
+	if err != nil {
+		exit(fmt.Errorf("unable to create bundle: %s", err))
+	}
+	raw, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		exit(err)
+	}
+	if err := os.WriteFile(*bundleFilename, raw, 0644); err != nil {
+		exit(fmt.Errorf("unable to write bundle: %s", err))
+	}
+	fmt.Printf("wrote bundle for block %d, receipt %d to %s\n", spec.Number, spec.Index, *bundleFilename)
+}
+
+func exit(msg error) {
+	fmt.Fprintf(os.Stderr, "%s\n", msg)
+	os.Exit(1)
+}
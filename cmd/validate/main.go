@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "path to the challenge directory")
+	solution := flag.String("solution", "", "path to a patch solving the challenge, relative to the challenge directory (e.g. from git diff --relative)")
+	flag.Parse()
+
+	// The unsolved challenge must not be captured.
+	fmt.Println("running checker on the unsolved challenge")
+	captured, err := runChecker(*dir)
+	if err != nil {
+		exit(err)
+	}
+	if captured {
+		exit(fmt.Errorf("flag captured without a solution"))
+	}
+	if *solution == "" {
+		fmt.Println("challenge fails as expected, no solution given")
+		return
+	}
+
+	// Apply the solution to a copy of the challenge, which must be captured.
+	fmt.Println("running checker on the solved challenge")
+	solved, err := solvedCopy(*dir, *solution)
+	if solved != "" {
+		defer os.RemoveAll(solved)
+	}
+	if err != nil {
+		exit(err)
+	}
+	if captured, err = runChecker(solved); err != nil {
+		exit(err)
+	}
+	if !captured {
+		exit(fmt.Errorf("flag not captured with the solution applied"))
+	}
+	fmt.Println("challenge valid")
+}
+
+// runChecker runs the challenge's checker in dir and reports whether the flag
+// was captured. Failures other than a missed flag, e.g. build errors, are
+// returned as errors.
+func runChecker(dir string) (bool, error) {
+	cmd := exec.Command("go", "run", ".", "--quiet")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	switch {
+	case err == nil && bytes.Contains(out, []byte("Flag captured.")):
+		return true, nil
+	case err != nil && bytes.Contains(out, []byte("Flag not captured")):
+		return false, nil
+	default:
+		return false, fmt.Errorf("checker failed: %v\n%s", err, out)
+	}
+}
+
+// solvedCopy copies the challenge directory to a temporary directory and
+// applies the solution patch to it. The copy is returned even on error, so the
+// caller can clean it up.
+func solvedCopy(dir, solution string) (string, error) {
+	if !filepath.IsAbs(solution) {
+		solution = filepath.Join(dir, solution)
+	}
+	patch, err := filepath.Abs(solution)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp("", "validate-")
+	if err != nil {
+		return "", err
+	}
+	if err := copyDir(dir, tmp); err != nil {
+		return tmp, fmt.Errorf("unable to copy challenge: %v", err)
+	}
+	if err := fixReplaces(dir, tmp); err != nil {
+		return tmp, fmt.Errorf("unable to update go.mod: %v", err)
+	}
+	cmd := exec.Command("git", "apply", patch)
+	cmd.Dir = tmp
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return tmp, fmt.Errorf("unable to apply solution: %v\n%s", err, out)
+	}
+	return tmp, nil
+}
+
+// copyDir recursively copies the files in src to dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// fixReplaces rewrites the relative replace directives in the copied go.mod
+// that point outside of the challenge directory, e.g. to the repository root,
+// as absolute paths. Replacements within the challenge, like its go-ethereum,
+// were copied along and are left alone.
+func fixReplaces(orig, copied string) error {
+	cmd := exec.Command("go", "mod", "edit", "-json")
+	cmd.Dir = orig
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+	var mod struct {
+		Replace []struct {
+			Old struct{ Path, Version string }
+			New struct{ Path, Version string }
+		}
+	}
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return err
+	}
+	root, err := filepath.Abs(orig)
+	if err != nil {
+		return err
+	}
+	for _, r := range mod.Replace {
+		if !strings.HasPrefix(r.New.Path, "./") && !strings.HasPrefix(r.New.Path, "../") {
+			continue
+		}
+		target := filepath.Join(root, r.New.Path)
+		if rel, err := filepath.Rel(root, target); err == nil && !strings.HasPrefix(rel, "..") {
+			continue
+		}
+		old := r.Old.Path
+		if r.Old.Version != "" {
+			old += "@" + r.Old.Version
+		}
+		cmd := exec.Command("go", "mod", "edit", "-replace", old+"="+target)
+		cmd.Dir = copied
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%v: %s", err, out)
+		}
+	}
+	return nil
+}
+
+func exit(msg error) {
+	fmt.Fprintf(os.Stderr, "%s\n", msg)
+	os.Exit(1)
+}
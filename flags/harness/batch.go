@@ -0,0 +1,37 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BatchCall is a JSON-RPC call issued by BatchVerify.
+type BatchCall struct {
+	Method string
+	Args   []interface{}
+}
+
+// BatchVerify issues all calls in a single JSON-RPC batch and returns their raw
+// results in the same order, for the caller to decode and check. It fails if
+// the batch or any of the calls failed.
+func BatchVerify(ctx context.Context, client *rpc.Client, calls []BatchCall) ([]json.RawMessage, error) {
+	var (
+		results = make([]json.RawMessage, len(calls))
+		batch   = make([]rpc.BatchElem, len(calls))
+	)
+	for i, call := range calls {
+		batch[i] = rpc.BatchElem{Method: call.Method, Args: call.Args, Result: &results[i]}
+	}
+	if err := client.BatchCallContext(ctx, batch); err != nil {
+		return nil, fmt.Errorf("batch failed: %v", err)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("call %d (%s) failed: %v", i, elem.Method, elem.Error)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,172 @@
+package harness
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by the waiting helpers. The real clock is
+// used by default, WithClock swaps in another one, e.g. a FakeClock in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+
+	// WithTimeout returns a copy of ctx that is cancelled once d elapsed on
+	// the clock, like context.WithTimeout.
+	WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc)
+}
+
+// RealClock is the system clock.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d)
+}
+
+type clockKey struct{}
+
+// WithClock returns a context making the helpers it is passed to use clock for
+// timeouts and polling.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// clockFrom returns the clock set on the context, or the real clock.
+func clockFrom(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return RealClock
+}
+
+// withTimeout returns a context that is cancelled once timeout elapsed on the
+// clock set on ctx, along with that clock.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, Clock) {
+	clock := clockFrom(ctx)
+	ctx, cancel := clock.WithTimeout(ctx, timeout)
+	return ctx, cancel, clock
+}
+
+// FakeClock is a Clock that only moves when advanced, so timeouts can be tested
+// deterministically without real sleeps. BlockUntil lets a test wait for the
+// code under test to start waiting before advancing the clock.
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond // Signalled when a timer is added
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+// fakeTimer is a pending After or WithTimeout of a FakeClock. Once expired,
+// the time is sent on ch, or fn is called if set.
+type fakeTimer struct {
+	when time.Time
+	ch   chan time.Time
+	fn   func()
+}
+
+// NewFakeClock creates a fake clock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the time once the clock advanced by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	t := &fakeTimer{ch: make(chan time.Time, 1)}
+	c.add(t, d)
+	return t.ch
+}
+
+// WithTimeout returns a copy of ctx that is cancelled by the Advance call
+// moving the clock d past its current time.
+func (c *FakeClock) WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &fakeTimer{fn: cancel}
+	c.add(t, d)
+	return ctx, func() {
+		c.remove(t)
+		cancel()
+	}
+}
+
+// Waiters returns the number of timers waiting for the clock to advance.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting for the clock to
+// advance.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// Advance moves the clock forward by d and fires the timers that expired.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.fire()
+	}
+	c.timers = pending
+}
+
+func (c *FakeClock) add(t *fakeTimer, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t.when = c.now.Add(d)
+	if d <= 0 {
+		t.fire()
+		return
+	}
+	c.timers = append(c.timers, t)
+	c.changed.Broadcast()
+}
+
+func (c *FakeClock) remove(t *fakeTimer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *fakeTimer) fire() {
+	if t.fn != nil {
+		t.fn()
+		return
+	}
+	t.ch <- t.when
+}
@@ -0,0 +1,24 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// VerifyEquivalent grades a solver against a reference solution. The reference
+// is run against the client and its result compared to the outcome claimed by
+// the solver. A nil eq compares the two with reflect.DeepEqual.
+func VerifyEquivalent(ctx context.Context, solverResult interface{}, reference func(ctx context.Context) (interface{}, error), eq func(a, b interface{}) bool) error {
+	want, err := reference(ctx)
+	if err != nil {
+		return fmt.Errorf("reference failed: %v", err)
+	}
+	if eq == nil {
+		eq = reflect.DeepEqual
+	}
+	if !eq(solverResult, want) {
+		return fmt.Errorf("result doesn't match reference (have %v, want %v)", solverResult, want)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package harness
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/lightclient/protocol-ctf/flags/verify"
+)
+
+// oracleABI is the interface solution oracles implement. The challenge is
+// solved once isSolved returns true.
+const oracleABI = `[{"name":"isSolved","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bool"}]}]`
+
+// DeployOracle deploys the creation bytecode of a solution oracle from key's
+// account, waiting up to timeout for it to be mined. The returned address is
+// where solvers find the oracle.
+//
+// The node must be mining, e.g. geth --dev. Against a node that only imports a
+// fixed chain, like the challenge verifiers' nodes, the deployment times out.
+func DeployOracle(ctx context.Context, eth *ethclient.Client, key *ecdsa.PrivateKey, bytecode []byte, timeout time.Duration) (common.Address, error) {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	chainID, err := eth.ChainID(ctx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("couldn't load chain id: %v", err)
+	}
+	nonce, err := eth.PendingNonceAt(ctx, from)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("couldn't load nonce: %v", err)
+	}
+	gasPrice, err := eth.SuggestGasPrice(ctx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("couldn't load gas price: %v", err)
+	}
+	gas, err := eth.EstimateGas(ctx, ethereum.CallMsg{From: from, Data: bytecode})
+	if err != nil {
+		return common.Address{}, fmt.Errorf("couldn't estimate deployment gas: %v", err)
+	}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gas,
+		Value:    new(big.Int),
+		Data:     bytecode,
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+	if err := eth.SendTransaction(ctx, tx); err != nil {
+		return common.Address{}, fmt.Errorf("couldn't submit oracle deployment: %v", err)
+	}
+	receipt, err := WaitConfirmations(ctx, eth, tx.Hash(), 0, timeout)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return common.Address{}, fmt.Errorf("oracle deployment %s failed", tx.Hash())
+	}
+	return receipt.ContractAddress, nil
+}
+
+// VerifyOracleSolved checks that the oracle at addr reports the challenge as
+// solved.
+func VerifyOracleSolved(ctx context.Context, eth *ethclient.Client, addr common.Address) error {
+	return verify.VerifyCallTuple(ctx, eth, addr, oracleABI, "isSolved", nil, []interface{}{true})
+}
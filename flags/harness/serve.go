@@ -0,0 +1,52 @@
+package harness
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ServeArtifacts serves the challenge's genesis and chain files over HTTP at
+// /genesis.json and /chain.rlp, so solvers can load them into their own node.
+// Responses are gzip compressed for clients accepting it. Like
+// http.ListenAndServe, it only returns on error.
+func ServeArtifacts(addr string, genesisPath, chainPath string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/genesis.json", serveFile(genesisPath, "application/json"))
+	mux.Handle("/chain.rlp", serveFile(chainPath, "application/octet-stream"))
+	return http.ListenAndServe(addr, mux)
+}
+
+// serveFile returns a handler serving the file at path with the given content
+// type. The file is read on every request, so it may be regenerated while the
+// server is running.
+func serveFile(path, contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			http.Error(w, "artifact unavailable", http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead {
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			io.Copy(w, f)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		io.Copy(gz, f)
+	})
+}
@@ -0,0 +1,78 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Solution is the format of solution.json files, submitted by solvers of
+// challenges with an on-chain win condition. Challenges solved by fixing the
+// client take a patch instead, see cmd/validate.
+type Solution struct {
+	Transactions []SolutionTx `json:"transactions"`
+}
+
+// SolutionTx is a signed transaction of a solution. Transactions are submitted
+// in the order they are listed.
+type SolutionTx struct {
+	Raw hexutil.Bytes `json:"raw"` // Signed transaction, as accepted by eth_sendRawTransaction
+
+	// WaitMined holds back the following transactions until this one is mined,
+	// e.g. to place them in later blocks.
+	WaitMined bool `json:"waitMined,omitempty"`
+}
+
+// LoadSolution reads a solution file.
+func LoadSolution(path string) (*Solution, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var solution Solution
+	if err := json.Unmarshal(raw, &solution); err != nil {
+		return nil, err
+	}
+	return &solution, nil
+}
+
+// ApplySolution submits the transactions of the solution file at path and
+// waits for all of them to be mined, allowing each up to timeout. Whether the
+// transactions succeeded is left to the flag check.
+//
+// The node must be mining, e.g. geth --dev. The challenge verifiers' nodes only
+// import a fixed chain and never include the transactions.
+func ApplySolution(ctx context.Context, eth *ethclient.Client, path string, timeout time.Duration) error {
+	solution, err := LoadSolution(path)
+	if err != nil {
+		return fmt.Errorf("couldn't load solution: %v", err)
+	}
+	var pending []*types.Transaction
+	for i, stx := range solution.Transactions {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(stx.Raw); err != nil {
+			return fmt.Errorf("invalid solution transaction %d: %v", i, err)
+		}
+		if err := eth.SendTransaction(ctx, tx); err != nil {
+			return fmt.Errorf("couldn't submit solution transaction %d: %v", i, err)
+		}
+		pending = append(pending, tx)
+		if stx.WaitMined {
+			if _, err := WaitConfirmations(ctx, eth, tx.Hash(), 0, timeout); err != nil {
+				return err
+			}
+		}
+	}
+	for _, tx := range pending {
+		if _, err := WaitConfirmations(ctx, eth, tx.Hash(), 0, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
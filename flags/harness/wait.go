@@ -0,0 +1,112 @@
+// Package harness contains helpers for setting up and driving challenges on a
+// running client.
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// pollInterval is the delay between consecutive polls while waiting.
+const pollInterval = 100 * time.Millisecond
+
+// WaitForLog polls for a log matching q until one appears or the timeout
+// elapses, and returns the first match.
+func WaitForLog(ctx context.Context, eth *ethclient.Client, q ethereum.FilterQuery, timeout time.Duration) (*types.Log, error) {
+	ctx, cancel, clock := withTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		logs, err := eth.FilterLogs(ctx, q)
+		if err != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("couldn't filter logs: %v", err)
+		}
+		if len(logs) > 0 {
+			return &logs[0], nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no matching log after %v", timeout)
+		case <-clock.After(pollInterval):
+		}
+	}
+}
+
+// WaitConfirmations waits until the transaction is included at least n blocks
+// below the head of the chain, and returns its receipt. It fails if a reorg
+// removes the transaction from the chain after it was included.
+func WaitConfirmations(ctx context.Context, eth *ethclient.Client, txHash common.Hash, n uint64, timeout time.Duration) (*types.Receipt, error) {
+	ctx, cancel, clock := withTimeout(ctx, timeout)
+	defer cancel()
+
+	var included bool
+	for {
+		receipt, err := eth.TransactionReceipt(ctx, txHash)
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			if included {
+				return nil, fmt.Errorf("transaction %s removed from the chain by a reorg", txHash)
+			}
+		case err != nil && ctx.Err() == nil:
+			return nil, fmt.Errorf("couldn't load receipt: %v", err)
+		case err == nil:
+			included = true
+			head, err := eth.BlockNumber(ctx)
+			if err != nil && ctx.Err() == nil {
+				return nil, fmt.Errorf("couldn't load head: %v", err)
+			}
+			if err == nil && head >= receipt.BlockNumber.Uint64()+n {
+				return receipt, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s not confirmed %d blocks deep after %v", txHash, n, timeout)
+		case <-clock.After(pollInterval):
+		}
+	}
+}
+
+// WaitSynced waits until the client reports it isn't syncing and has a head
+// above genesis, i.e. it finished syncing rather than never started. On timeout
+// the error tells the two apart.
+func WaitSynced(ctx context.Context, eth *ethclient.Client, timeout time.Duration) error {
+	ctx, cancel, clock := withTimeout(ctx, timeout)
+	defer cancel()
+
+	var last *ethereum.SyncProgress
+	for {
+		progress, err := eth.SyncProgress(ctx)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("couldn't load sync progress: %v", err)
+		}
+		if err == nil {
+			if progress != nil {
+				last = progress
+			} else {
+				head, err := eth.BlockNumber(ctx)
+				if err != nil && ctx.Err() == nil {
+					return fmt.Errorf("couldn't load head: %v", err)
+				}
+				if err == nil && head > 0 {
+					return nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if last == nil {
+				return fmt.Errorf("sync never started after %v", timeout)
+			}
+			return fmt.Errorf("sync not complete after %v (at block %d of %d)", timeout, last.CurrentBlock, last.HighestBlock)
+		case <-clock.After(pollInterval):
+		}
+	}
+}
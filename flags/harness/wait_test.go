@@ -0,0 +1,59 @@
+package harness
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// pendingEth is an eth namespace that never includes any transaction.
+type pendingEth struct{}
+
+func (pendingEth) GetTransactionReceipt(txHash common.Hash) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// TestWaitConfirmationsTimeout drives WaitConfirmations to its timeout with a
+// fake clock, polling a node that never includes the transaction.
+func TestWaitConfirmationsTimeout(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", pendingEth{}); err != nil {
+		t.Fatal(err)
+	}
+	eth := ethclient.NewClient(rpc.DialInProc(server))
+	defer eth.Close()
+
+	var (
+		clock   = NewFakeClock(time.Unix(0, 0))
+		ctx     = WithClock(context.Background(), clock)
+		timeout = 10 * pollInterval
+		errc    = make(chan error, 1)
+	)
+	go func() {
+		_, err := WaitConfirmations(ctx, eth, common.Hash{1}, 1, timeout)
+		errc <- err
+	}()
+	// Each round, wait for the timeout and the next poll to be pending.
+	for i := 0; i < 9; i++ {
+		clock.BlockUntil(2)
+		clock.Advance(pollInterval)
+	}
+	clock.BlockUntil(2)
+	select {
+	case err := <-errc:
+		t.Fatalf("returned before the timeout: %v", err)
+	default:
+	}
+	clock.Advance(pollInterval)
+
+	err := <-errc
+	if err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Fatalf("wrong error (have %v, want timeout)", err)
+	}
+}
@@ -0,0 +1,142 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VerifyNonce checks the nonce of addr at the latest block.
+func VerifyNonce(ctx context.Context, eth *ethclient.Client, addr common.Address, want uint64) error {
+	nonce, err := eth.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't load nonce: %v", err)
+	}
+	if nonce != want {
+		return fmt.Errorf("wrong nonce for %s (have %d, want %d)", addr, nonce, want)
+	}
+	return nil
+}
+
+// VerifyPendingNonce checks the nonce of addr in the pending state, which
+// includes transactions still in the pool.
+func VerifyPendingNonce(ctx context.Context, eth *ethclient.Client, addr common.Address, want uint64) error {
+	nonce, err := eth.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("couldn't load pending nonce: %v", err)
+	}
+	if nonce != want {
+		return fmt.Errorf("wrong pending nonce for %s (have %d, want %d)", addr, nonce, want)
+	}
+	return nil
+}
+
+// BalanceSum returns the sum of the balances of addrs at the given block. A nil
+// block means the latest block.
+func BalanceSum(ctx context.Context, eth *ethclient.Client, addrs []common.Address, atBlock *big.Int) (*big.Int, error) {
+	sum := new(big.Int)
+	for _, addr := range addrs {
+		balance, err := eth.BalanceAt(ctx, addr, atBlock)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load balance of %s: %v", addr, err)
+		}
+		sum.Add(sum, balance)
+	}
+	return sum, nil
+}
+
+// VerifyBalanceSum checks the sum of the balances of addrs at the given block.
+func VerifyBalanceSum(ctx context.Context, eth *ethclient.Client, addrs []common.Address, want *big.Int, atBlock *big.Int) error {
+	sum, err := BalanceSum(ctx, eth, addrs, atBlock)
+	if err != nil {
+		return err
+	}
+	if sum.Cmp(want) != 0 {
+		return fmt.Errorf("wrong balance sum (have %v, want %v)", sum, want)
+	}
+	return nil
+}
+
+// VerifyBalanceSumDelta checks that the sum of the balances of addrs changed by
+// wantDelta between the two blocks. A zero delta asserts value was conserved.
+func VerifyBalanceSumDelta(ctx context.Context, eth *ethclient.Client, addrs []common.Address, wantDelta *big.Int, fromBlock, toBlock *big.Int) error {
+	before, err := BalanceSum(ctx, eth, addrs, fromBlock)
+	if err != nil {
+		return err
+	}
+	after, err := BalanceSum(ctx, eth, addrs, toBlock)
+	if err != nil {
+		return err
+	}
+	if delta := new(big.Int).Sub(after, before); delta.Cmp(wantDelta) != 0 {
+		return fmt.Errorf("wrong balance sum change (have %v, want %v)", delta, wantDelta)
+	}
+	return nil
+}
+
+// StorageRoot returns the root of addr's storage trie at the given block, as
+// reported by eth_getProof. A nil block means the latest block.
+func StorageRoot(ctx context.Context, client *rpc.Client, addr common.Address, atBlock *big.Int) (common.Hash, error) {
+	proof, err := gethclient.New(client).GetProof(ctx, addr, nil, atBlock)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("couldn't load proof of %s: %v", addr, err)
+	}
+	return proof.StorageHash, nil
+}
+
+// VerifyStorageRoot checks the root of addr's storage trie at the given block,
+// which asserts the contract's entire storage at once.
+func VerifyStorageRoot(ctx context.Context, client *rpc.Client, addr common.Address, want common.Hash, atBlock *big.Int) error {
+	root, err := StorageRoot(ctx, client, addr, atBlock)
+	if err != nil {
+		return err
+	}
+	if root != want {
+		return fmt.Errorf("wrong storage root for %s (have %s, want %s)", addr, root, want)
+	}
+	return nil
+}
+
+// VerifyNonceSequence checks that the transactions sent by addr in canonical
+// blocks from through to use contiguous nonces, starting at the account's
+// nonce before the range. The first out of sequence transaction is reported.
+func VerifyNonceSequence(ctx context.Context, eth *ethclient.Client, addr common.Address, from, to uint64) error {
+	if err := checkRange(from, to); err != nil {
+		return err
+	}
+	var next uint64
+	if from > 0 {
+		nonce, err := eth.NonceAt(ctx, addr, new(big.Int).SetUint64(from-1))
+		if err != nil {
+			return fmt.Errorf("couldn't load nonce: %v", err)
+		}
+		next = nonce
+	}
+	for n := from; n <= to; n++ {
+		block, err := eth.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return fmt.Errorf("couldn't load block %d: %v", n, err)
+		}
+		for i, tx := range block.Transactions() {
+			// The sender is cached from the block response, so this doesn't
+			// cost another round-trip.
+			sender, err := eth.TransactionSender(ctx, tx, block.Hash(), uint(i))
+			if err != nil {
+				return fmt.Errorf("couldn't recover sender of %s: %v", tx.Hash(), err)
+			}
+			if sender != addr {
+				continue
+			}
+			if tx.Nonce() != next {
+				return fmt.Errorf("nonce out of sequence in block %d: transaction %s has nonce %d, want %d", n, tx.Hash(), tx.Nonce(), next)
+			}
+			next++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package verify
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestVerifyNonceSequence(t *testing.T) {
+	node := newTxCountNode(t)
+	for _, tt := range []struct {
+		from, to uint64
+		valid    bool
+	}{
+		{from: 0, to: 3, valid: true},
+		{from: 2, to: 3, valid: true},
+		{from: 3, to: 3, valid: true},
+		{from: 3, to: 1},              // reversed range
+		{from: 1, to: math.MaxUint64}, // unbounded range
+		{from: 1, to: 4},              // past the head
+	} {
+		err := VerifyNonceSequence(context.Background(), node.eth, testAddr, tt.from, tt.to)
+		if tt.valid && err != nil {
+			t.Errorf("blocks %d-%d: valid sequence rejected: %v", tt.from, tt.to, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("blocks %d-%d: invalid range accepted", tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// accountRangePageSize is the number of accounts requested per page by
+// AllAccounts.
+const accountRangePageSize = 256
+
+// AccountRange lists up to max accounts of the state at the given block,
+// starting at the hashed key start, using debug_accountRange. It returns the
+// addresses in the page, sorted, and the key to continue from, which is nil
+// once all accounts have been listed.
+//
+// Addresses can only be listed if the node has their preimages, so the node
+// must be configured to record preimages (--cache.preimages in geth). Rather
+// than silently skipping accounts without a preimage, an error is returned.
+func AccountRange(ctx context.Context, client *rpc.Client, block common.Hash, start []byte, max int) ([]common.Address, []byte, error) {
+	var result struct {
+		Accounts map[common.Address]struct {
+			Key hexutil.Bytes `json:"key"`
+		} `json:"accounts"`
+		Next []byte `json:"next"`
+	}
+	err := client.CallContext(ctx, &result, "debug_accountRange", block, hexutil.Bytes(start), max, true, true, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't list accounts: %v", err)
+	}
+	addrs := make([]common.Address, 0, len(result.Accounts))
+	for addr, account := range result.Accounts {
+		// Accounts without a preimage are listed under the zero address,
+		// which can be told apart from the zero address itself by its key.
+		if addr == (common.Address{}) && !bytes.Equal(account.Key, crypto.Keccak256(addr[:])) {
+			return nil, nil, fmt.Errorf("missing address preimage for account %x", []byte(account.Key))
+		}
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs, result.Next, nil
+}
+
+// AllAccounts lists every account of the state at the given block by paging
+// through AccountRange.
+func AllAccounts(ctx context.Context, client *rpc.Client, block common.Hash) ([]common.Address, error) {
+	var (
+		all  []common.Address
+		next []byte
+	)
+	for {
+		addrs, n, err := AccountRange(ctx, client, block, next, accountRangePageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, addrs...)
+		if len(n) == 0 {
+			break
+		}
+		next = n
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return bytes.Compare(all[i][:], all[j][:]) < 0
+	})
+	return all, nil
+}
@@ -0,0 +1,302 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// RawBlock returns the RLP encoding of the canonical block n, in the same format
+// chain.rlp files are made of.
+func RawBlock(ctx context.Context, client *rpc.Client, n uint64) ([]byte, error) {
+	block, err := ethclient.NewClient(client).BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	return rlp.EncodeToBytes(block)
+}
+
+// VerifyRawBlock checks that the RLP encoding of canonical block n matches.
+func VerifyRawBlock(ctx context.Context, client *rpc.Client, n uint64, wantRLP []byte) error {
+	raw, err := RawBlock(ctx, client, n)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(raw, wantRLP) {
+		return fmt.Errorf("block %d doesn't match (have %x, want %x)", n, raw, wantRLP)
+	}
+	return nil
+}
+
+// VerifyBlockGasUsed compares the gas used by canonical block n against want.
+// The comparison cmp is one of "eq", "gte" or "lte".
+func VerifyBlockGasUsed(ctx context.Context, eth *ethclient.Client, n uint64, cmp string, want uint64) error {
+	header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	return compare(fmt.Sprintf("gas used in block %d", n), cmp, header.GasUsed, want)
+}
+
+// VerifyUncleCount checks the number of uncles included in canonical block n.
+func VerifyUncleCount(ctx context.Context, eth *ethclient.Client, n uint64, want int) error {
+	block, err := eth.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if have := len(block.Uncles()); have != want {
+		return fmt.Errorf("wrong uncle count in block %d (have %d, want %d)", n, have, want)
+	}
+	return nil
+}
+
+// VerifyGenesis checks that the client loaded the expected genesis block.
+func VerifyGenesis(ctx context.Context, eth *ethclient.Client, want common.Hash) error {
+	header, err := eth.HeaderByNumber(ctx, common.Big0)
+	if err != nil {
+		return fmt.Errorf("couldn't load genesis: %v", err)
+	}
+	if have := header.Hash(); have != want {
+		return fmt.Errorf("wrong genesis (have %s, want %s)", have, want)
+	}
+	return nil
+}
+
+// VerifyBlockTimestamp checks that the timestamp of canonical block n lies
+// within [wantMin, wantMax].
+func VerifyBlockTimestamp(ctx context.Context, eth *ethclient.Client, n uint64, wantMin, wantMax uint64) error {
+	header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if header.Time < wantMin || header.Time > wantMax {
+		return fmt.Errorf("wrong timestamp in block %d (have %d, want %d-%d)", n, header.Time, wantMin, wantMax)
+	}
+	return nil
+}
+
+// VerifyBlockDifficulty checks the difficulty of canonical block n. Blocks
+// after the merge have a difficulty of zero.
+func VerifyBlockDifficulty(ctx context.Context, eth *ethclient.Client, n uint64, want *big.Int) error {
+	if want == nil {
+		return errors.New("no difficulty to compare against")
+	}
+	header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if header.Difficulty.Cmp(want) != 0 {
+		return fmt.Errorf("wrong difficulty in block %d (have %v, want %v)", n, header.Difficulty, want)
+	}
+	return nil
+}
+
+// VerifyTotalTxCount compares the number of transactions in canonical blocks
+// from through to, inclusive, against want. The comparison cmp is one of "eq",
+// "gte" or "lte". Only the counts are requested, not the blocks themselves.
+func VerifyTotalTxCount(ctx context.Context, client *rpc.Client, from, to uint64, cmp string, want int) error {
+	if want < 0 {
+		return fmt.Errorf("invalid transaction count %d", want)
+	}
+	if err := checkRange(from, to); err != nil {
+		return err
+	}
+	var total uint64
+	for n := from; n <= to; n++ {
+		var count *hexutil.Uint
+		if err := client.CallContext(ctx, &count, "eth_getBlockTransactionCountByNumber", hexutil.EncodeUint64(n)); err != nil {
+			return fmt.Errorf("couldn't load transaction count of block %d: %v", n, err)
+		}
+		if count == nil {
+			return fmt.Errorf("couldn't load transaction count of block %d: %v", n, ethereum.NotFound)
+		}
+		total += uint64(*count)
+	}
+	return compare(fmt.Sprintf("transaction count in blocks %d-%d", from, to), cmp, total, uint64(want))
+}
+
+// ErrTxRootMismatch is returned when a transactions root doesn't match, as
+// opposed to failing to load the block.
+var ErrTxRootMismatch = errors.New("transactions root mismatch")
+
+// VerifyTxRoot checks the transactions root in the header of canonical block n.
+// With recompute set, the root is also derived locally from the block's
+// transactions, to cross-check the client's own computation.
+func VerifyTxRoot(ctx context.Context, eth *ethclient.Client, n uint64, wantRoot common.Hash, recompute bool) error {
+	block, err := eth.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if have := block.TxHash(); have != wantRoot {
+		return fmt.Errorf("%w in block %d (have %s, want %s)", ErrTxRootMismatch, n, have, wantRoot)
+	}
+	if recompute {
+		if derived := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); derived != block.TxHash() {
+			return fmt.Errorf("%w in block %d, header has %s but transactions derive %s", ErrTxRootMismatch, n, block.TxHash(), derived)
+		}
+	}
+	return nil
+}
+
+// VerifyBaseFee checks the base fee of canonical block n. A nil want asserts
+// the block predates London and has no base fee.
+func VerifyBaseFee(ctx context.Context, eth *ethclient.Client, n uint64, want *big.Int) error {
+	header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	switch {
+	case want == nil && header.BaseFee != nil:
+		return fmt.Errorf("unexpected base fee in block %d (have %v, want none)", n, header.BaseFee)
+	case want != nil && header.BaseFee == nil:
+		return fmt.Errorf("missing base fee in block %d (want %v)", n, want)
+	case want != nil && header.BaseFee.Cmp(want) != 0:
+		return fmt.Errorf("wrong base fee in block %d (have %v, want %v)", n, header.BaseFee, want)
+	}
+	return nil
+}
+
+// VerifyChainGasUsed checks that the gas used by canonical blocks from through
+// to, inclusive, adds up to at most maxTotal.
+func VerifyChainGasUsed(ctx context.Context, eth *ethclient.Client, from, to uint64, maxTotal uint64) error {
+	if err := checkRange(from, to); err != nil {
+		return err
+	}
+	var total uint64
+	for n := from; n <= to; n++ {
+		header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return fmt.Errorf("couldn't load block %d: %v", n, err)
+		}
+		total += header.GasUsed
+		if total > maxTotal {
+			return fmt.Errorf("gas used in blocks %d-%d exceeds cap at block %d (have %d, max %d)", from, to, n, total, maxTotal)
+		}
+	}
+	return nil
+}
+
+// checkRange validates the block range from through to, inclusive. The range
+// may not end at the largest block number, which no chain reaches and on which
+// loops over the range wouldn't terminate.
+func checkRange(from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	if to == math.MaxUint64 {
+		return fmt.Errorf("block range %d-%d out of bounds", from, to)
+	}
+	return nil
+}
+
+// VerifyPoWSeal checks that canonical block n carries a valid ethash seal and
+// difficulty, independent of whether the client verified it. Headers are
+// checked against their parent with the given chain config, so an invalid
+// difficulty is reported along with the expected one.
+func VerifyPoWSeal(ctx context.Context, eth *ethclient.Client, n uint64, config *params.ChainConfig) error {
+	return verifyPoWSeal(ctx, eth, n, config, sharedEthash())
+}
+
+// verifyPoWSeal is VerifyPoWSeal with the engine checking the seal.
+func verifyPoWSeal(ctx context.Context, eth *ethclient.Client, n uint64, config *params.ChainConfig, engine consensus.Engine) error {
+	if n == 0 {
+		return errors.New("genesis block has no seal")
+	}
+	header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if header.Difficulty.Sign() == 0 {
+		return fmt.Errorf("block %d is a proof-of-stake block", n)
+	}
+	parent, err := eth.HeaderByHash(ctx, header.ParentHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load parent of block %d: %v", n, err)
+	}
+	if err := engine.VerifyHeader(&headerReader{config: config, parent: parent}, header, true); err != nil {
+		return fmt.Errorf("invalid block %d: %v", n, err)
+	}
+	return nil
+}
+
+var (
+	ethashOnce   sync.Once
+	ethashEngine *ethash.Ethash
+)
+
+// sharedEthash returns the ethash engine shared by all seal checks, so that the
+// verification cache of an epoch is only generated once.
+func sharedEthash() *ethash.Ethash {
+	ethashOnce.Do(func() {
+		ethashEngine = ethash.New(ethash.Config{PowMode: ethash.ModeNormal}, nil, false)
+	})
+	return ethashEngine
+}
+
+// TotalDifficultyAt returns the total difficulty of the chain up to and
+// including canonical block n. It isn't part of the header, so it is read from
+// the raw eth_getBlockByNumber response.
+func TotalDifficultyAt(ctx context.Context, client *rpc.Client, n uint64) (*big.Int, error) {
+	var block *struct {
+		TotalDifficulty *hexutil.Big `json:"totalDifficulty"`
+	}
+	if err := client.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(n), false); err != nil {
+		return nil, fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("couldn't load block %d: %v", n, ethereum.NotFound)
+	}
+	if block.TotalDifficulty == nil {
+		return nil, fmt.Errorf("no total difficulty for block %d", n)
+	}
+	return block.TotalDifficulty.ToInt(), nil
+}
+
+// VerifyTTDCrossed checks that the total difficulty of the chain reached ttd,
+// and returns the first canonical block at which it did.
+func VerifyTTDCrossed(ctx context.Context, client *rpc.Client, ttd *big.Int) (uint64, error) {
+	head, err := ethclient.NewClient(client).BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't load head block: %v", err)
+	}
+	td, err := TotalDifficultyAt(ctx, client, head)
+	if err != nil {
+		return 0, err
+	}
+	if td.Cmp(ttd) < 0 {
+		return 0, fmt.Errorf("total difficulty below ttd at head block %d (have %v, want %v)", head, td, ttd)
+	}
+	// The total difficulty only grows, so search for the first block reaching it.
+	var searchErr error
+	n := sort.Search(int(head), func(i int) bool {
+		td, err := TotalDifficultyAt(ctx, client, uint64(i))
+		if err != nil {
+			if searchErr == nil {
+				searchErr = err
+			}
+			return false
+		}
+		return td.Cmp(ttd) >= 0
+	})
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	return uint64(n), nil
+}
@@ -0,0 +1,122 @@
+package verify
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// sealingEngine seals the proof-of-work blocks up to sealUntil as soon as they
+// are assembled, like chainmaker -seal real does.
+type sealingEngine struct {
+	*ethash.Ethash
+	sealUntil uint64
+}
+
+func (e *sealingEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	block, err := e.Ethash.FinalizeAndAssemble(chain, header, state, txs, uncles, receipts)
+	if err != nil || block.NumberU64() > e.sealUntil {
+		return block, err
+	}
+	results := make(chan *types.Block, 1)
+	if err := e.Ethash.Seal(chain, block, results, nil); err != nil {
+		return nil, err
+	}
+	return <-results, nil
+}
+
+func TestVerifyPoWSeal(t *testing.T) {
+	// Mining with the full dataset is too slow for tests, so seal and verify
+	// with the small test dataset instead.
+	engine := ethash.NewTester(nil, false)
+	t.Cleanup(func() { engine.Close() })
+
+	gspec := testGenesis(nil)
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, receipts := core.GenerateChain(gspec.Config, genesis, &sealingEngine{engine, 2}, db, 3, nil)
+	node := startTestNode(t, gspec, blocks, receipts)
+
+	ctx := context.Background()
+	for n, valid := range []bool{false, true, true, false} {
+		err := verifyPoWSeal(ctx, node.eth, uint64(n), gspec.Config, engine)
+		if valid && err != nil {
+			t.Errorf("block %d: valid seal rejected: %v", n, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("block %d: invalid seal accepted", n)
+		}
+	}
+}
+
+// newTxCountNode serves blocks 1 to 3 holding 2, 0 and 1 transfers, each using
+// 21000 gas.
+func newTxCountNode(t *testing.T) *testNode {
+	return newTestNode(t, nil, 3, func(i int, b *core.BlockGen) {
+		for j := 0; j < []int{2, 0, 1}[i]; j++ {
+			sendTx(b, &common.Address{0xaa}, nil, 21000, nil)
+		}
+	})
+}
+
+func TestVerifyTotalTxCount(t *testing.T) {
+	node := newTxCountNode(t)
+	for _, tt := range []struct {
+		from, to uint64
+		cmp      string
+		want     int
+		valid    bool
+	}{
+		{from: 1, to: 3, cmp: "eq", want: 3, valid: true},
+		{from: 2, to: 2, cmp: "eq", want: 0, valid: true},
+		{from: 0, to: 3, cmp: "gte", want: 2, valid: true},
+		{from: 1, to: 2, cmp: "lte", want: 2, valid: true},
+		{from: 1, to: 3, cmp: "gte", want: 4},
+		{from: 1, to: 3, cmp: "lte", want: 2},
+		{from: 1, to: 3, cmp: "ne", want: 3},
+		{from: 1, to: 3, cmp: "eq", want: -1},
+		{from: 3, to: 1, cmp: "eq", want: 0},
+		{from: 1, to: math.MaxUint64, cmp: "gte", want: 0},
+		{from: 1, to: 4, cmp: "gte", want: 0},
+	} {
+		err := VerifyTotalTxCount(context.Background(), node.rpc, tt.from, tt.to, tt.cmp, tt.want)
+		if tt.valid && err != nil {
+			t.Errorf("blocks %d-%d %s %d: valid count rejected: %v", tt.from, tt.to, tt.cmp, tt.want, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("blocks %d-%d %s %d: invalid count accepted", tt.from, tt.to, tt.cmp, tt.want)
+		}
+	}
+}
+
+func TestVerifyChainGasUsed(t *testing.T) {
+	node := newTxCountNode(t)
+	for _, tt := range []struct {
+		from, to uint64
+		max      uint64
+		valid    bool
+	}{
+		{from: 1, to: 3, max: 3 * 21000, valid: true},
+		{from: 2, to: 2, max: 0, valid: true},
+		{from: 1, to: 3, max: 3*21000 - 1},
+		{from: 3, to: 1, max: math.MaxUint64},
+		{from: 1, to: math.MaxUint64, max: math.MaxUint64},
+		{from: 1, to: 4, max: math.MaxUint64},
+	} {
+		err := VerifyChainGasUsed(context.Background(), node.eth, tt.from, tt.to, tt.max)
+		if tt.valid && err != nil {
+			t.Errorf("blocks %d-%d max %d: valid gas used rejected: %v", tt.from, tt.to, tt.max, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("blocks %d-%d max %d: invalid gas used accepted", tt.from, tt.to, tt.max)
+		}
+	}
+}
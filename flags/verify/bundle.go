@@ -0,0 +1,229 @@
+// Package verify contains reusable primitives for grading challenges.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Bundle is a self-contained proof that a receipt was included in a chain. It
+// can be checked without running a node or touching the network, which makes it
+// suitable for graders that can't spawn processes.
+//
+// The headers link the receipt's block to a block the grader trusts, usually
+// the challenge's genesis, so solvers can't make up a chain of their own.
+type Bundle struct {
+	Headers []hexutil.Bytes `json:"headers"` // RLP encoded headers, from the child of the trusted block to the receipt's block
+	Index   uint64          `json:"index"`   // Position of the receipt in the last block
+	Receipt hexutil.Bytes   `json:"receipt"` // Consensus encoding of the receipt
+	Proof   []hexutil.Bytes `json:"proof"`   // Receipt trie nodes from root to leaf
+}
+
+// NewBundle creates a bundle proving the inclusion of receipts[index] in the
+// last of the headers. The headers must be consecutive, starting at the child of
+// the block the grader trusts.
+func NewBundle(headers []*types.Header, receipts types.Receipts, index int) (*Bundle, error) {
+	if len(headers) == 0 {
+		return nil, errors.New("no headers")
+	}
+	header := headers[len(headers)-1]
+	if index < 0 || index >= len(receipts) {
+		return nil, fmt.Errorf("receipt index %d out of range (have %d)", index, len(receipts))
+	}
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	for i, r := range receipts {
+		key, _ := rlp.EncodeToBytes(uint(i))
+		val, err := r.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		tr.Update(key, val)
+	}
+	if tr.Hash() != header.ReceiptHash {
+		return nil, fmt.Errorf("receipts don't match header (have %x, want %x)", tr.Hash(), header.ReceiptHash)
+	}
+	key, _ := rlp.EncodeToBytes(uint(index))
+	proof := new(proofList)
+	if err := tr.Prove(key, 0, proof); err != nil {
+		return nil, err
+	}
+	b := &Bundle{Index: uint64(index), Proof: *proof}
+	for _, h := range headers {
+		enc, err := rlp.EncodeToBytes(h)
+		if err != nil {
+			return nil, err
+		}
+		b.Headers = append(b.Headers, enc)
+	}
+	var err error
+	if b.Receipt, err = receipts[index].MarshalBinary(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// FetchBundle creates a bundle proving the receipt of the transaction at index
+// in canonical block n. The bundle holds the headers of blocks 1 through n, so
+// it is anchored at the genesis.
+func FetchBundle(ctx context.Context, eth *ethclient.Client, n uint64, index int) (*Bundle, error) {
+	if n == 0 {
+		return nil, errors.New("genesis block has no receipts")
+	}
+	headers := make([]*types.Header, 0, n)
+	for i := uint64(1); i <= n; i++ {
+		header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(i))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load block %d: %v", i, err)
+		}
+		headers = append(headers, header)
+	}
+	block, err := eth.BlockByHash(ctx, headers[n-1].Hash())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	receipts := make(types.Receipts, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if receipts[i], err = eth.TransactionReceipt(ctx, tx.Hash()); err != nil {
+			return nil, fmt.Errorf("couldn't load receipt of %s: %v", tx.Hash(), err)
+		}
+	}
+	return NewBundle(headers, receipts, index)
+}
+
+// LoadBundle reads a JSON encoded bundle from disk.
+func LoadBundle(path string) (*Bundle, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Verify checks that the bundle's headers form a valid chain descending from
+// the trusted header, verifying each header's consensus fields and seal against
+// its parent. It then checks the receipt's Merkle proof against the receipt
+// root of the last header. If fakePow is set, seals are not checked, which is
+// required for chains generated by chainmaker. On success, the proven receipt
+// and the headers are returned.
+func (b *Bundle) Verify(config *params.ChainConfig, trusted *types.Header, fakePow bool) (*types.Receipt, []*types.Header, error) {
+	if len(b.Headers) == 0 {
+		return nil, nil, errors.New("no headers")
+	}
+	var engine *ethash.Ethash
+	if fakePow {
+		engine = ethash.NewFaker()
+	} else {
+		engine = ethash.New(ethash.Config{PowMode: ethash.ModeNormal}, nil, false)
+	}
+	defer engine.Close()
+
+	var (
+		headers = make([]*types.Header, len(b.Headers))
+		parent  = trusted
+	)
+	for i, enc := range b.Headers {
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(enc, header); err != nil {
+			return nil, nil, fmt.Errorf("invalid header %d: %v", i, err)
+		}
+		if header.ParentHash != parent.Hash() {
+			return nil, nil, fmt.Errorf("header %d parent mismatch (have %x, want %x)", i, header.ParentHash, parent.Hash())
+		}
+		if err := engine.VerifyHeader(&headerReader{config: config, parent: parent}, header, true); err != nil {
+			return nil, nil, fmt.Errorf("invalid header %d: %v", i, err)
+		}
+		headers[i], parent = header, header
+	}
+	header := headers[len(headers)-1]
+
+	// Verify the receipt is included in the header's receipt trie.
+	db := memorydb.New()
+	for _, node := range b.Proof {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	key, _ := rlp.EncodeToBytes(uint(b.Index))
+	val, err := trie.VerifyProof(header.ReceiptHash, key, db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid receipt proof: %v", err)
+	}
+	if val == nil {
+		return nil, nil, errors.New("receipt not included in block")
+	}
+	if !bytes.Equal(val, b.Receipt) {
+		return nil, nil, errors.New("proven receipt doesn't match bundle receipt")
+	}
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(val); err != nil {
+		return nil, nil, fmt.Errorf("invalid receipt: %v", err)
+	}
+	return receipt, headers, nil
+}
+
+// headerReader is a minimal consensus.ChainHeaderReader which only knows about
+// the parent of the header being verified.
+type headerReader struct {
+	config *params.ChainConfig
+	parent *types.Header
+}
+
+var _ consensus.ChainHeaderReader = (*headerReader)(nil)
+
+func (r *headerReader) Config() *params.ChainConfig  { return r.config }
+func (r *headerReader) CurrentHeader() *types.Header { return r.parent }
+
+func (r *headerReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if hash == r.parent.Hash() && number == r.parent.Number.Uint64() {
+		return r.parent
+	}
+	return nil
+}
+
+func (r *headerReader) GetHeaderByNumber(number uint64) *types.Header {
+	if number == r.parent.Number.Uint64() {
+		return r.parent
+	}
+	return nil
+}
+
+func (r *headerReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	if hash == r.parent.Hash() {
+		return r.parent
+	}
+	return nil
+}
+
+func (r *headerReader) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
+
+// proofList collects the trie nodes of a Merkle proof in order.
+type proofList []hexutil.Bytes
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
@@ -0,0 +1,69 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBundle(t *testing.T) {
+	node := newTestNode(t, nil, 3, func(i int, b *core.BlockGen) {
+		sendTx(b, &common.Address{0xaa}, nil, 21000, nil)
+		sendTx(b, &common.Address{0xbb}, nil, 21000, nil)
+	})
+	var (
+		trusted = node.genesis.ToBlock().Header()
+		headers = make([]*types.Header, len(node.blocks))
+	)
+	for i, b := range node.blocks {
+		headers[i] = b.Header()
+	}
+	fetched, err := FetchBundle(context.Background(), node.eth, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name    string
+		headers []*types.Header
+		index   int
+		trusted *types.Header
+		valid   bool
+	}{
+		{name: "full chain", headers: headers, index: 1, trusted: trusted, valid: true},
+		{name: "first receipt", headers: headers, index: 0, trusted: trusted, valid: true},
+		{name: "untrusted parent", headers: headers[1:], index: 1, trusted: trusted},
+		{name: "missing header", headers: append(headers[:1:1], headers[2]), index: 1, trusted: trusted},
+	} {
+		bundle, err := NewBundle(tt.headers, node.receipts[2], tt.index)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		receipt, proven, err := bundle.Verify(node.genesis.Config, tt.trusted, true)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("%s: invalid bundle accepted", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: valid bundle rejected: %v", tt.name, err)
+			continue
+		}
+		if len(proven) != len(tt.headers) || proven[len(proven)-1].Hash() != node.blocks[2].Hash() {
+			t.Errorf("%s: wrong headers returned", tt.name)
+		}
+		if receipt.CumulativeGasUsed != node.receipts[2][tt.index].CumulativeGasUsed {
+			t.Errorf("%s: wrong receipt (have cumulative gas %d, want %d)", tt.name, receipt.CumulativeGasUsed, node.receipts[2][tt.index].CumulativeGasUsed)
+		}
+	}
+	// A bundle fetched from the node matches the one made from the chain.
+	if _, _, err := fetched.Verify(node.genesis.Config, trusted, true); err != nil {
+		t.Fatalf("fetched bundle rejected: %v", err)
+	}
+	if want, _ := NewBundle(headers, node.receipts[2], 1); string(want.Receipt) != string(fetched.Receipt) {
+		t.Errorf("wrong fetched receipt (have %x, want %x)", fetched.Receipt, want.Receipt)
+	}
+}
@@ -0,0 +1,159 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// VerifyCallTuple calls method on the contract at to and compares each returned
+// value against want.
+//
+// Expected values are coerced to the returned types where it is unambiguous:
+// integers may be given as any Go integer, *big.Int or numeric string, and
+// addresses as common.Address or hex string. Tuples may be given as a list of
+// their components, a map keyed by component name or a struct with the same
+// fields, and are compared component by component.
+func VerifyCallTuple(ctx context.Context, eth *ethclient.Client, to common.Address, abiJSON, method string, args []interface{}, want []interface{}) error {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't encode call to %s: %v", method, err)
+	}
+	out, err := eth.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("call to %s failed: %v", method, err)
+	}
+	have, err := parsed.Unpack(method, out)
+	if err != nil {
+		return fmt.Errorf("couldn't decode result of %s: %v", method, err)
+	}
+	if len(have) != len(want) {
+		return fmt.Errorf("wrong number of return values from %s (have %d, want %d)", method, len(have), len(want))
+	}
+	var mismatches []string
+	for i := range have {
+		if !equalValue(have[i], want[i]) {
+			name := parsed.Methods[method].Outputs[i].Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			mismatches = append(mismatches, fmt.Sprintf("%s: have %v, want %v", name, have[i], want[i]))
+		}
+	}
+	if len(mismatches) != 0 {
+		return fmt.Errorf("wrong return values from %s:\n%s", method, strings.Join(mismatches, "\n"))
+	}
+	return nil
+}
+
+// VerifyImmutable calls the getter with the given selector on proxy and
+// compares the returned data against want, which is ABI encoded, e.g.
+// common.LeftPadBytes(addr.Bytes(), 32) for an address.
+//
+// The call is sent to the proxy itself, so it works through EIP-1167 minimal
+// proxies and clones with immutable args, which append the args to the
+// calldata they forward to the implementation.
+func VerifyImmutable(ctx context.Context, eth *ethclient.Client, proxy common.Address, getterSelector []byte, want []byte) error {
+	out, err := eth.CallContract(ctx, ethereum.CallMsg{To: &proxy, Data: getterSelector}, nil)
+	if err != nil {
+		return fmt.Errorf("call to %x on %s failed: %v", getterSelector, proxy, err)
+	}
+	if !bytes.Equal(out, want) {
+		return fmt.Errorf("wrong immutable from %x on %s (have %x, want %x)", getterSelector, proxy, out, want)
+	}
+	return nil
+}
+
+// equalValue compares an ABI decoded value against an expected value, coercing
+// integers and addresses.
+func equalValue(have, want interface{}) bool {
+	if _, isString := have.(string); isString {
+		return have == want
+	}
+	if h, ok := toBig(have); ok {
+		w, ok := toBig(want)
+		return ok && h.Cmp(w) == 0
+	}
+	if h, ok := have.(common.Address); ok {
+		switch w := want.(type) {
+		case common.Address:
+			return h == w
+		case string:
+			return common.IsHexAddress(w) && h == common.HexToAddress(w)
+		}
+		return false
+	}
+	if h := reflect.ValueOf(have); h.Kind() == reflect.Struct {
+		return equalTuple(h, want)
+	}
+	return reflect.DeepEqual(have, want)
+}
+
+// equalTuple compares a decoded tuple, which the abi package returns as an
+// anonymous struct, component by component against want.
+func equalTuple(have reflect.Value, want interface{}) bool {
+	switch w := want.(type) {
+	case []interface{}:
+		if len(w) != have.NumField() {
+			return false
+		}
+		for i := range w {
+			if !equalValue(have.Field(i).Interface(), w[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		if len(w) != have.NumField() {
+			return false
+		}
+		for i := 0; i < have.NumField(); i++ {
+			v, ok := w[have.Type().Field(i).Tag.Get("json")]
+			if !ok || !equalValue(have.Field(i).Interface(), v) {
+				return false
+			}
+		}
+		return true
+	}
+	w := reflect.ValueOf(want)
+	if w.Kind() != reflect.Struct || w.NumField() != have.NumField() {
+		return false
+	}
+	for i := 0; i < have.NumField(); i++ {
+		f := w.FieldByName(have.Type().Field(i).Name)
+		if !f.IsValid() || !f.CanInterface() || !equalValue(have.Field(i).Interface(), f.Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// toBig converts any integer representation to a big.Int.
+func toBig(v interface{}) (*big.Int, bool) {
+	switch v := v.(type) {
+	case *big.Int:
+		return v, v != nil
+	case string:
+		return new(big.Int).SetString(v, 0)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), true
+	}
+	return nil, false
+}
@@ -0,0 +1,58 @@
+package verify
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+const tupleABI = `[{"type":"function","name":"get","stateMutability":"view","inputs":[],"outputs":[
+	{"name":"t","type":"tuple","components":[{"name":"a","type":"uint256"},{"name":"b","type":"address"}]},
+	{"name":"ok","type":"bool"}
+]}]`
+
+// tupleCode returns ((7, 0xaa..), true) to any call.
+func tupleCode() []byte {
+	data := make([]byte, 96)
+	data[31], data[44], data[95] = 7, 0xaa, 1
+	code := []byte{
+		byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	return append(code, data...)
+}
+
+func TestVerifyCallTuple(t *testing.T) {
+	contract := common.Address{0xcc}
+	node := newTestNode(t, core.GenesisAlloc{contract: {Code: tupleCode()}}, 0, nil)
+
+	type tuple struct {
+		A *big.Int
+		B common.Address
+	}
+	for _, tt := range []struct {
+		name  string
+		want  interface{}
+		valid bool
+	}{
+		{name: "list", want: []interface{}{7, "0xaa00000000000000000000000000000000000000"}, valid: true},
+		{name: "map", want: map[string]interface{}{"a": "7", "b": common.Address{0xaa}}, valid: true},
+		{name: "struct", want: tuple{big.NewInt(7), common.Address{0xaa}}, valid: true},
+		{name: "wrong component", want: []interface{}{8, common.Address{0xaa}}},
+		{name: "missing component", want: []interface{}{7}},
+		{name: "wrong name", want: map[string]interface{}{"a": 7, "c": common.Address{0xaa}}},
+		{name: "not a tuple", want: 7},
+	} {
+		err := VerifyCallTuple(context.Background(), node.eth, contract, tupleABI, "get", nil, []interface{}{tt.want, true})
+		if tt.valid && err != nil {
+			t.Errorf("%s: valid result rejected: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: invalid result accepted", tt.name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// AccountState is the state of an account captured in a checkpoint.
+type AccountState struct {
+	Balance *big.Int
+	Nonce   uint64
+}
+
+// StateCheckpoint is a snapshot of selected accounts at a block, taken before
+// the solver acts so grading can be relative to it.
+type StateCheckpoint struct {
+	Number   uint64
+	Accounts map[common.Address]*AccountState
+}
+
+// StateChange is the expected change of an account relative to a checkpoint.
+type StateChange struct {
+	Address      common.Address
+	BalanceDelta *big.Int // Expected balance change, nil to skip the check
+	NonceDelta   *uint64  // Expected number of transactions sent, nil to skip the check
+}
+
+// Checkpoint captures the state of addrs at the current head block.
+func Checkpoint(ctx context.Context, eth *ethclient.Client, addrs []common.Address) (*StateCheckpoint, error) {
+	head, err := eth.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load head block number: %v", err)
+	}
+	cp := &StateCheckpoint{
+		Number:   head,
+		Accounts: make(map[common.Address]*AccountState),
+	}
+	for _, addr := range addrs {
+		if cp.Accounts[addr], err = accountState(ctx, eth, addr, new(big.Int).SetUint64(head)); err != nil {
+			return nil, err
+		}
+	}
+	return cp, nil
+}
+
+// VerifyAgainstCheckpoint checks that each listed account changed by the
+// expected amounts between the checkpoint and the current head.
+func VerifyAgainstCheckpoint(ctx context.Context, eth *ethclient.Client, cp *StateCheckpoint, changes []StateChange) error {
+	var failures []string
+	for _, change := range changes {
+		before, ok := cp.Accounts[change.Address]
+		if !ok {
+			return fmt.Errorf("account %s not in checkpoint", change.Address)
+		}
+		after, err := accountState(ctx, eth, change.Address, nil)
+		if err != nil {
+			return err
+		}
+		if change.BalanceDelta != nil {
+			if delta := new(big.Int).Sub(after.Balance, before.Balance); delta.Cmp(change.BalanceDelta) != 0 {
+				failures = append(failures, fmt.Sprintf("%s: wrong balance change (have %v, want %v)", change.Address, delta, change.BalanceDelta))
+			}
+		}
+		if change.NonceDelta != nil {
+			if delta := after.Nonce - before.Nonce; delta != *change.NonceDelta {
+				failures = append(failures, fmt.Sprintf("%s: wrong nonce change (have %d, want %d)", change.Address, delta, *change.NonceDelta))
+			}
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("state doesn't match checkpoint %d:\n%s", cp.Number, strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// VerifyUntouched checks that the balance, nonce, code and storage of addrs at
+// the head are the same as at the checkpoint. The accounts don't need to be part
+// of the checkpoint, but the client must still have the state of its block.
+func VerifyUntouched(ctx context.Context, client *rpc.Client, addrs []common.Address, cp *StateCheckpoint) error {
+	geth := gethclient.New(client)
+	var failures []string
+	for _, addr := range addrs {
+		before, err := geth.GetProof(ctx, addr, nil, new(big.Int).SetUint64(cp.Number))
+		if err != nil {
+			return fmt.Errorf("couldn't load state of %s at checkpoint: %v", addr, err)
+		}
+		after, err := geth.GetProof(ctx, addr, nil, nil)
+		if err != nil {
+			return fmt.Errorf("couldn't load state of %s: %v", addr, err)
+		}
+		if before.Balance.Cmp(after.Balance) != 0 {
+			failures = append(failures, fmt.Sprintf("%s: balance changed from %v to %v", addr, before.Balance, after.Balance))
+		}
+		if before.Nonce != after.Nonce {
+			failures = append(failures, fmt.Sprintf("%s: nonce changed from %d to %d", addr, before.Nonce, after.Nonce))
+		}
+		if before.CodeHash != after.CodeHash {
+			failures = append(failures, fmt.Sprintf("%s: code hash changed from %s to %s", addr, before.CodeHash, after.CodeHash))
+		}
+		if before.StorageHash != after.StorageHash {
+			failures = append(failures, fmt.Sprintf("%s: storage root changed from %s to %s", addr, before.StorageHash, after.StorageHash))
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("accounts touched since checkpoint %d:\n%s", cp.Number, strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// VerifyBlocksProduced checks that exactly wantDelta blocks were added on top of
+// the baseline head number.
+func VerifyBlocksProduced(ctx context.Context, eth *ethclient.Client, baseline uint64, wantDelta uint64) error {
+	head, err := eth.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't load head block number: %v", err)
+	}
+	if head < baseline {
+		return fmt.Errorf("head %d is below baseline %d", head, baseline)
+	}
+	if delta := head - baseline; delta != wantDelta {
+		return fmt.Errorf("wrong number of blocks produced since %d (have %d, want %d)", baseline, delta, wantDelta)
+	}
+	return nil
+}
+
+// VerifyBlocksSinceCheckpoint checks that exactly wantDelta blocks were added
+// since the checkpoint was taken.
+func VerifyBlocksSinceCheckpoint(ctx context.Context, eth *ethclient.Client, cp *StateCheckpoint, wantDelta uint64) error {
+	return VerifyBlocksProduced(ctx, eth, cp.Number, wantDelta)
+}
+
+// accountState reads the state of addr at the given block.
+func accountState(ctx context.Context, eth *ethclient.Client, addr common.Address, number *big.Int) (*AccountState, error) {
+	balance, err := eth.BalanceAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load balance of %s: %v", addr, err)
+	}
+	nonce, err := eth.NonceAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load nonce of %s: %v", addr, err)
+	}
+	return &AccountState{Balance: balance, Nonce: nonce}, nil
+}
@@ -0,0 +1,102 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// VerifyCreate2 computes the address CREATE2 deploys to for the given deployer,
+// salt and init code hash, and checks whether code exists there. The computed
+// address is included in the error.
+func VerifyCreate2(ctx context.Context, eth *ethclient.Client, deployer common.Address, salt common.Hash, initCodeHash common.Hash, wantCode bool) error {
+	addr := crypto.CreateAddress2(deployer, salt, initCodeHash.Bytes())
+	code, err := eth.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't load code at %s: %v", addr, err)
+	}
+	if hasCode := len(code) != 0; hasCode != wantCode {
+		return fmt.Errorf("wrong code presence at create2 address %s (have %t, want %t)", addr, hasCode, wantCode)
+	}
+	return nil
+}
+
+// ExtCodeHash returns the value the EXTCODEHASH opcode would push for addr at
+// the latest block.
+//
+// Per EIP-1052, accounts that don't exist or are empty as defined by EIP-161
+// (zero nonce, zero balance and no code) hash to zero. Any other account hashes
+// to the keccak256 of its code, so an account with a balance but no code hashes
+// to keccak256(""), not zero.
+func ExtCodeHash(ctx context.Context, eth *ethclient.Client, addr common.Address) (common.Hash, error) {
+	code, err := eth.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("couldn't load code of %s: %v", addr, err)
+	}
+	if len(code) == 0 {
+		nonce, err := eth.NonceAt(ctx, addr, nil)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("couldn't load nonce of %s: %v", addr, err)
+		}
+		balance, err := eth.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("couldn't load balance of %s: %v", addr, err)
+		}
+		if nonce == 0 && balance.Sign() == 0 {
+			return common.Hash{}, nil
+		}
+	}
+	return crypto.Keccak256Hash(code), nil
+}
+
+// VerifyExtCodeHash checks the EXTCODEHASH of addr. See ExtCodeHash for how
+// empty accounts are handled.
+func VerifyExtCodeHash(ctx context.Context, eth *ethclient.Client, addr common.Address, want common.Hash) error {
+	hash, err := ExtCodeHash(ctx, eth, addr)
+	if err != nil {
+		return err
+	}
+	if hash != want {
+		return fmt.Errorf("wrong extcodehash for %s (have %s, want %s)", addr, hash, want)
+	}
+	return nil
+}
+
+// VerifyCodeEquals checks that the runtime code of addr at the latest block is
+// byte-for-byte equal to wantCode.
+func VerifyCodeEquals(ctx context.Context, eth *ethclient.Client, addr common.Address, wantCode []byte) error {
+	code, err := eth.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't load code of %s: %v", addr, err)
+	}
+	for i := 0; i < len(code) || i < len(wantCode); i++ {
+		if i >= len(code) || i >= len(wantCode) || code[i] != wantCode[i] {
+			return fmt.Errorf("wrong code at %s (have %d bytes, want %d bytes, first difference at byte %d)", addr, len(code), len(wantCode), i)
+		}
+	}
+	return nil
+}
+
+// VerifyCodeEqualsFile is like VerifyCodeEquals, but reads the reference code
+// from a file holding hex, with or without a 0x prefix.
+func VerifyCodeEqualsFile(ctx context.Context, eth *ethclient.Client, addr common.Address, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("couldn't read reference code: %v", err)
+	}
+	str := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(str, "0x") {
+		str = "0x" + str
+	}
+	wantCode, err := hexutil.Decode(str)
+	if err != nil {
+		return fmt.Errorf("invalid reference code in %s: %v", path, err)
+	}
+	return VerifyCodeEquals(ctx, eth, addr, wantCode)
+}
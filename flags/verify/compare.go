@@ -0,0 +1,25 @@
+package verify
+
+import "fmt"
+
+// comparisons maps the comparison names accepted by the verifiers to the
+// operator used in error messages.
+var comparisons = map[string]string{
+	"eq":  "==",
+	"gte": ">=",
+	"lte": "<=",
+}
+
+// compare checks have against want using the comparison named by cmp, one of
+// "eq", "gte" or "lte". The error describes what, e.g. "gas used in block 1".
+func compare(what string, cmp string, have, want uint64) error {
+	op, ok := comparisons[cmp]
+	if !ok {
+		return fmt.Errorf("unknown comparison %q", cmp)
+	}
+	switch {
+	case cmp == "eq" && have == want, cmp == "gte" && have >= want, cmp == "lte" && have <= want:
+		return nil
+	}
+	return fmt.Errorf("wrong %s (have %d, want %s %d)", what, have, op, want)
+}
@@ -0,0 +1,97 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Deployer returns the address that created the contract at addr, which is the
+// sender for contracts created by a transaction and the factory for contracts
+// created by another contract.
+//
+// The creating block is found by searching for the first block with code at
+// addr, whose transactions are then traced. This requires the state of past
+// blocks, and doesn't find contracts deployed without code.
+func Deployer(ctx context.Context, client *rpc.Client, addr common.Address) (common.Address, error) {
+	eth := ethclient.NewClient(client)
+	head, err := eth.BlockNumber(ctx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("couldn't load head block: %v", err)
+	}
+	hasCode := func(n uint64) (bool, error) {
+		code, err := eth.CodeAt(ctx, addr, new(big.Int).SetUint64(n))
+		if err != nil {
+			return false, fmt.Errorf("couldn't load code at %s in block %d: %v", addr, n, err)
+		}
+		return len(code) != 0, nil
+	}
+	if ok, err := hasCode(head); err != nil {
+		return common.Address{}, err
+	} else if !ok {
+		return common.Address{}, fmt.Errorf("no contract at %s", addr)
+	}
+	var searchErr error
+	n := uint64(sort.Search(int(head), func(i int) bool {
+		ok, err := hasCode(uint64(i))
+		if err != nil && searchErr == nil {
+			searchErr = err
+		}
+		return ok
+	}))
+	if searchErr != nil {
+		return common.Address{}, searchErr
+	}
+	if n == 0 {
+		return common.Address{}, fmt.Errorf("contract %s was allocated in genesis", addr)
+	}
+	block, err := eth.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	for _, tx := range block.Transactions() {
+		call, err := traceCalls(ctx, client, tx.Hash())
+		if err != nil {
+			return common.Address{}, err
+		}
+		if creator, ok := findCreator(call, addr); ok {
+			return creator, nil
+		}
+	}
+	return common.Address{}, fmt.Errorf("no creation of %s found in block %d", addr, n)
+}
+
+// findCreator searches the frame and its nested calls for a successful creation
+// of addr, returning the creating address.
+func findCreator(f *callFrame, addr common.Address) (common.Address, bool) {
+	if f.Error != "" {
+		return common.Address{}, false
+	}
+	if (f.Type == "CREATE" || f.Type == "CREATE2") && f.To == addr {
+		return f.From, true
+	}
+	for i := range f.Calls {
+		if creator, ok := findCreator(&f.Calls[i], addr); ok {
+			return creator, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// VerifyDeployedBy checks that the contract at addr was created by
+// wantDeployer, see Deployer.
+func VerifyDeployedBy(ctx context.Context, client *rpc.Client, addr common.Address, wantDeployer common.Address) error {
+	deployer, err := Deployer(ctx, client, addr)
+	if err != nil {
+		return err
+	}
+	if deployer != wantDeployer {
+		return fmt.Errorf("wrong deployer of %s (have %s, want %s)", addr, deployer, wantDeployer)
+	}
+	return nil
+}
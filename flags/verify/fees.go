@@ -0,0 +1,65 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// VerifyCoinbase checks the fee recipient (miner field) of canonical block n.
+func VerifyCoinbase(ctx context.Context, eth *ethclient.Client, n uint64, want common.Address) error {
+	header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	if header.Coinbase != want {
+		return fmt.Errorf("wrong coinbase of block %d (have %s, want %s)", n, header.Coinbase, want)
+	}
+	return nil
+}
+
+// BlockFees returns the priority fees paid to the coinbase of canonical block n
+// and the base fees burned by its transactions. Block and uncle rewards are not
+// included.
+func BlockFees(ctx context.Context, eth *ethclient.Client, n uint64) (tips, burned *big.Int, err error) {
+	block, err := eth.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't load block %d: %v", n, err)
+	}
+	tips, burned = new(big.Int), new(big.Int)
+	for _, tx := range block.Transactions() {
+		receipt, err := eth.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't load receipt of %s: %v", tx.Hash(), err)
+		}
+		gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+		tip, err := tx.EffectiveGasTip(block.BaseFee())
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid fees of %s: %v", tx.Hash(), err)
+		}
+		tips.Add(tips, tip.Mul(tip, gasUsed))
+		if block.BaseFee() != nil {
+			burned.Add(burned, new(big.Int).Mul(block.BaseFee(), gasUsed))
+		}
+	}
+	return tips, burned, nil
+}
+
+// VerifyFeesReceived checks that addr was the fee recipient of canonical block
+// n and earned want in priority fees from its transactions.
+func VerifyFeesReceived(ctx context.Context, eth *ethclient.Client, addr common.Address, n uint64, want *big.Int) error {
+	if err := VerifyCoinbase(ctx, eth, n, addr); err != nil {
+		return err
+	}
+	tips, burned, err := BlockFees(ctx, eth, n)
+	if err != nil {
+		return err
+	}
+	if tips.Cmp(want) != 0 {
+		return fmt.Errorf("wrong fees received by %s in block %d (have %v, want %v, %v burned)", addr, n, tips, want, burned)
+	}
+	return nil
+}
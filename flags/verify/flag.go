@@ -0,0 +1,32 @@
+package verify
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FlagSpec is the completion criteria of a generated challenge, written to
+// flag.json by chainmaker. The flag is captured when the client loads the chain
+// up to the given block. Bundles must prove the receipt of the transaction at
+// Index in that block.
+type FlagSpec struct {
+	Genesis common.Hash `json:"genesis"`
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Index   uint64      `json:"index"`
+}
+
+// LoadFlagSpec reads a JSON encoded flag spec from disk.
+func LoadFlagSpec(path string) (*FlagSpec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec FlagSpec
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
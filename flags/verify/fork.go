@@ -0,0 +1,89 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// forkFeatures detect whether a fork is active in a block, by the header fields
+// it introduced. Headers are inspected as raw JSON, so forks newer than the
+// go-ethereum version of this module are recognized as well.
+var forkFeatures = map[string]func(header map[string]json.RawMessage) bool{
+	"london": func(h map[string]json.RawMessage) bool { return present(h["baseFeePerGas"]) },
+	"merge": func(h map[string]json.RawMessage) bool {
+		var difficulty *hexutil.Big
+		return json.Unmarshal(h["difficulty"], &difficulty) == nil && difficulty != nil && difficulty.ToInt().Sign() == 0
+	},
+	"shanghai": func(h map[string]json.RawMessage) bool { return present(h["withdrawalsRoot"]) },
+	"cancun":   func(h map[string]json.RawMessage) bool { return present(h["parentBeaconBlockRoot"]) },
+}
+
+// present reports whether a header field was set.
+func present(field json.RawMessage) bool {
+	return len(field) != 0 && string(field) != "null"
+}
+
+// ForkBlock returns the first canonical block in which fork is active. Known
+// forks are london, merge, shanghai and cancun.
+func ForkBlock(ctx context.Context, client *rpc.Client, fork string) (uint64, error) {
+	active, ok := forkFeatures[strings.ToLower(fork)]
+	if !ok {
+		return 0, fmt.Errorf("unknown fork %q", fork)
+	}
+	var head hexutil.Uint64
+	if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return 0, fmt.Errorf("couldn't load head block: %v", err)
+	}
+	var err error
+	activeAt := func(n uint64) bool {
+		var header map[string]json.RawMessage
+		if err == nil {
+			if err = client.CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(n), false); err == nil && header == nil {
+				err = fmt.Errorf("block %d not found", n)
+			}
+		}
+		return err == nil && active(header)
+	}
+	if !activeAt(uint64(head)) {
+		if err != nil {
+			return 0, fmt.Errorf("couldn't load block %d: %v", head, err)
+		}
+		return 0, fmt.Errorf("%s not active at head block %d", fork, head)
+	}
+	// Forks stay active once activated, so search for the boundary.
+	n := uint64(sort.Search(int(head), func(i int) bool { return activeAt(uint64(i)) }))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't load block: %v", err)
+	}
+	return n, nil
+}
+
+// VerifyForkBlock checks that fork activated at canonical block wantBlock, i.e.
+// it is active there but not in the parent.
+func VerifyForkBlock(ctx context.Context, client *rpc.Client, fork string, wantBlock uint64) error {
+	n, err := ForkBlock(ctx, client, fork)
+	if err != nil {
+		return err
+	}
+	if n != wantBlock {
+		return fmt.Errorf("wrong %s activation block (have %d, want %d)", fork, n, wantBlock)
+	}
+	return nil
+}
+
+// ClientVersion returns the client's version string as reported by
+// web3_clientVersion, e.g. "Geth/v1.10.23-stable/linux-amd64/go1.19", so that
+// graders can adapt checks to the client under test.
+func ClientVersion(ctx context.Context, client *rpc.Client) (string, error) {
+	var version string
+	if err := client.CallContext(ctx, &version, "web3_clientVersion"); err != nil {
+		return "", fmt.Errorf("couldn't load client version: %v", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,20 @@
+package verify
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestClientVersion(t *testing.T) {
+	node := newTestNode(t, nil, 0, nil)
+	version, err := ClientVersion(context.Background(), node.rpc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(version, "Geth") || !strings.Contains(version, params.VersionWithMeta) {
+		t.Errorf("unexpected client version %q, want Geth/v%s", version, params.VersionWithMeta)
+	}
+}
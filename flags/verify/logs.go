@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// LogMatcher describes a log expected to be emitted.
+type LogMatcher struct {
+	Address common.Address // Contract emitting the log
+	Topics  []common.Hash  // Leading topics of the log, matched by position
+	Data    []byte         // Log data, nil matches any data
+}
+
+// Matches reports whether the log satisfies the matcher.
+func (m *LogMatcher) Matches(log *types.Log) bool {
+	if log.Address != m.Address || len(log.Topics) < len(m.Topics) {
+		return false
+	}
+	for i, topic := range m.Topics {
+		if log.Topics[i] != topic {
+			return false
+		}
+	}
+	return m.Data == nil || bytes.Equal(log.Data, m.Data)
+}
+
+func (m *LogMatcher) String() string {
+	return fmt.Sprintf("{address: %s, topics: %v, data: %x}", m.Address, m.Topics, m.Data)
+}
+
+// VerifyLogsInTx checks that every matcher is satisfied by a distinct log
+// emitted by the transaction. Logs may appear in any order.
+func VerifyLogsInTx(ctx context.Context, eth *ethclient.Client, txHash common.Hash, wants []LogMatcher) error {
+	receipt, err := eth.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load receipt: %v", err)
+	}
+	// Assign logs to matchers. A log can satisfy several matchers, so search
+	// for alternative assignments before giving up on a matcher.
+	var (
+		owner  = make([]int, len(receipt.Logs)) // Matcher assigned to each log, -1 if none
+		failed []string
+	)
+	for i := range owner {
+		owner[i] = -1
+	}
+	var assign func(m int, seen []bool) bool
+	assign = func(m int, seen []bool) bool {
+		for i, log := range receipt.Logs {
+			if seen[i] || !wants[m].Matches(log) {
+				continue
+			}
+			seen[i] = true
+			if owner[i] == -1 || assign(owner[i], seen) {
+				owner[i] = m
+				return true
+			}
+		}
+		return false
+	}
+	for m := range wants {
+		if !assign(m, make([]bool, len(receipt.Logs))) {
+			failed = append(failed, fmt.Sprintf("%d: %s", m, &wants[m]))
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("unmatched logs in transaction %s:\n%s", txHash, strings.Join(failed, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+var (
+	topicA = common.Hash{0xa}
+	topicB = common.Hash{0xb}
+)
+
+// logCode emits a log with topics A and B, then one with topic A only, both
+// without data.
+func logCode() []byte {
+	code := []byte{byte(vm.PUSH32)}
+	code = append(code, topicB[:]...)
+	code = append(code, byte(vm.PUSH32))
+	code = append(code, topicA[:]...)
+	code = append(code, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG2), byte(vm.PUSH32))
+	code = append(code, topicA[:]...)
+	return append(code, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1), byte(vm.STOP))
+}
+
+func TestVerifyLogsInTx(t *testing.T) {
+	contract := common.Address{0xcc}
+	var txHash common.Hash
+	node := newTestNode(t, core.GenesisAlloc{contract: {Code: logCode()}}, 1, func(i int, b *core.BlockGen) {
+		txHash = sendTx(b, &contract, nil, 100000, nil).Hash()
+	})
+	for _, tt := range []struct {
+		name  string
+		wants []LogMatcher
+		valid bool
+	}{
+		// The first matcher also fits the first log, which the second needs.
+		{name: "overlapping", wants: []LogMatcher{{Address: contract, Topics: []common.Hash{topicA}}, {Address: contract, Topics: []common.Hash{topicA, topicB}}}, valid: true},
+		{name: "same matcher twice", wants: []LogMatcher{{Address: contract, Topics: []common.Hash{topicA}}, {Address: contract, Topics: []common.Hash{topicA}}}, valid: true},
+		{name: "empty data", wants: []LogMatcher{{Address: contract, Data: []byte{}}}, valid: true},
+		{name: "more matchers than logs", wants: []LogMatcher{{Address: contract}, {Address: contract}, {Address: contract}}},
+		{name: "wrong topic", wants: []LogMatcher{{Address: contract, Topics: []common.Hash{topicB}}}},
+		{name: "wrong data", wants: []LogMatcher{{Address: contract, Data: []byte{1}}}},
+		{name: "wrong address", wants: []LogMatcher{{Address: common.Address{0xdd}}}},
+	} {
+		err := VerifyLogsInTx(context.Background(), node.eth, txHash, tt.wants)
+		if tt.valid && err != nil {
+			t.Errorf("%s: valid logs rejected: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: invalid logs accepted", tt.name)
+		}
+	}
+}
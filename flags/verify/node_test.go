@@ -0,0 +1,114 @@
+package verify
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// testNode is an in-process node serving a generated chain.
+type testNode struct {
+	rpc      *rpc.Client
+	eth      *ethclient.Client
+	genesis  *core.Genesis
+	blocks   []*types.Block
+	receipts []types.Receipts
+}
+
+// newTestNode generates n blocks on top of a genesis funding testAddr and
+// holding the accounts in alloc, and serves them from an in-process node.
+func newTestNode(t *testing.T, alloc core.GenesisAlloc, n int, gen func(i int, b *core.BlockGen)) *testNode {
+	t.Helper()
+	gspec := testGenesis(alloc)
+	db := rawdb.NewMemoryDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, receipts := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, gen)
+	return startTestNode(t, gspec, blocks, receipts)
+}
+
+// testGenesis returns a genesis funding testAddr and holding the accounts in
+// alloc.
+func testGenesis(alloc core.GenesisAlloc) *core.Genesis {
+	gspec := &core.Genesis{
+		Config:     params.AllEthashProtocolChanges,
+		Alloc:      core.GenesisAlloc{testAddr: {Balance: big.NewInt(1e18)}},
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(1234),
+		GasLimit:   30_000_000,
+	}
+	for addr, account := range alloc {
+		if account.Balance == nil {
+			account.Balance = new(big.Int)
+		}
+		gspec.Alloc[addr] = account
+	}
+	return gspec
+}
+
+// startTestNode serves the chain from an in-process node. The node doesn't
+// verify seals, so the chain may be generated with any ethash mode.
+func startTestNode(t *testing.T, gspec *core.Genesis, blocks []*types.Block, receipts []types.Receipts) *testNode {
+	t.Helper()
+	stack, err := node.New(&node.Config{
+		Name:    "Geth",
+		Version: params.VersionWithMeta,
+		P2P:     p2p.Config{NoDiscovery: true, NoDial: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stack.Close() })
+	config := ethconfig.Defaults
+	config.Genesis, config.NetworkId, config.Preimages = gspec, 1337, true
+	config.Ethash.PowMode = ethash.ModeFake
+	backend, err := eth.New(stack, &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	if _, err := backend.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatal(err)
+	}
+	client, err := stack.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testNode{rpc: client, eth: ethclient.NewClient(client), genesis: gspec, blocks: blocks, receipts: receipts}
+}
+
+// sendTx adds a transaction from testAddr to the block.
+func sendTx(b *core.BlockGen, to *common.Address, value *big.Int, gas uint64, data []byte) *types.Transaction {
+	tx := types.MustSignNewTx(testKey, types.LatestSigner(params.AllEthashProtocolChanges), &types.LegacyTx{
+		Nonce:    b.TxNonce(testAddr),
+		To:       to,
+		Value:    value,
+		Gas:      gas,
+		GasPrice: b.BaseFee(),
+		Data:     data,
+	})
+	b.AddTx(tx)
+	return tx
+}
@@ -0,0 +1,69 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoPendingBlock is returned when the client doesn't construct a pending
+// block and serves the latest block in its place.
+var ErrNoPendingBlock = errors.New("client has no pending block")
+
+// PendingBlock returns the block the client is currently building on top of
+// the head of the chain.
+//
+// The pending block has no hash or seal, which ethclient refuses to decode, so
+// it is loaded with a raw eth_getBlockByNumber call.
+func PendingBlock(ctx context.Context, client *rpc.Client) (*types.Block, error) {
+	// Load the head first, so that a block mined in between the two calls can't
+	// make the pending block look stale.
+	head, err := ethclient.NewClient(client).HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load latest block: %v", err)
+	}
+	var raw json.RawMessage
+	if err := client.CallContext(ctx, &raw, "eth_getBlockByNumber", "pending", true); err != nil {
+		return nil, fmt.Errorf("couldn't load pending block: %v", err)
+	}
+	if string(raw) == "null" {
+		return nil, ErrNoPendingBlock
+	}
+	var (
+		header types.Header
+		body   struct {
+			Transactions []*types.Transaction `json:"transactions"`
+		}
+	)
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("invalid pending block: %v", err)
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, fmt.Errorf("invalid pending block: %v", err)
+	}
+	if header.Number.Cmp(head.Number) <= 0 {
+		return nil, ErrNoPendingBlock
+	}
+	return types.NewBlockWithHeader(&header).WithBody(body.Transactions, nil), nil
+}
+
+// VerifyPendingContainsTx checks that the transaction is included in the
+// pending block.
+func VerifyPendingContainsTx(ctx context.Context, client *rpc.Client, txHash common.Hash) error {
+	block, err := PendingBlock(ctx, client)
+	if err != nil {
+		return err
+	}
+	for _, tx := range block.Transactions() {
+		if tx.Hash() == txHash {
+			return nil
+		}
+	}
+	return fmt.Errorf("transaction %s not in pending block %d", txHash, block.Number())
+}
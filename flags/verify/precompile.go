@@ -0,0 +1,72 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Addresses of the standard precompiled contracts.
+var (
+	PrecompileEcrecover       = common.BytesToAddress([]byte{0x01})
+	PrecompileSHA256          = common.BytesToAddress([]byte{0x02})
+	PrecompileRIPEMD160       = common.BytesToAddress([]byte{0x03})
+	PrecompileIdentity        = common.BytesToAddress([]byte{0x04})
+	PrecompileModExp          = common.BytesToAddress([]byte{0x05})
+	PrecompileBN256Add        = common.BytesToAddress([]byte{0x06})
+	PrecompileBN256ScalarMul  = common.BytesToAddress([]byte{0x07})
+	PrecompileBN256Pairing    = common.BytesToAddress([]byte{0x08})
+	PrecompileBlake2F         = common.BytesToAddress([]byte{0x09})
+	PrecompilePointEvaluation = common.BytesToAddress([]byte{0x0a})
+)
+
+// precompileNames names the standard precompiles in error messages.
+var precompileNames = map[common.Address]string{
+	PrecompileEcrecover:       "ecrecover",
+	PrecompileSHA256:          "sha256",
+	PrecompileRIPEMD160:       "ripemd160",
+	PrecompileIdentity:        "identity",
+	PrecompileModExp:          "modexp",
+	PrecompileBN256Add:        "bn256Add",
+	PrecompileBN256ScalarMul:  "bn256ScalarMul",
+	PrecompileBN256Pairing:    "bn256Pairing",
+	PrecompileBlake2F:         "blake2f",
+	PrecompilePointEvaluation: "pointEvaluation",
+}
+
+// VerifyPrecompileCalled traces the transaction and checks that it called the
+// precompile at the given address, either directly or from a contract.
+func VerifyPrecompileCalled(ctx context.Context, client *rpc.Client, txHash common.Hash, precompile common.Address) error {
+	call, err := traceCalls(ctx, client, txHash)
+	if err != nil {
+		return err
+	}
+	var (
+		found  bool
+		called []string
+		seen   = make(map[common.Address]bool)
+	)
+	call.each(func(f *callFrame) {
+		if f.To == precompile {
+			found = true
+		}
+		if name, ok := precompileNames[f.To]; ok && !seen[f.To] {
+			seen[f.To] = true
+			called = append(called, name)
+		}
+	})
+	if found {
+		return nil
+	}
+	want := precompile.Hex()
+	if name, ok := precompileNames[precompile]; ok {
+		want = name
+	}
+	if len(called) == 0 {
+		return fmt.Errorf("transaction %s didn't call %s, nor any other precompile", txHash, want)
+	}
+	return fmt.Errorf("transaction %s didn't call %s, only %s", txHash, want, strings.Join(called, ", "))
+}
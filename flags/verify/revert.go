@@ -0,0 +1,46 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNotReverted is returned when a transaction expected to revert succeeded.
+var ErrNotReverted = errors.New("transaction did not revert")
+
+// VerifyRevert checks that the transaction reverted with the given reason.
+//
+// The revert data isn't part of the receipt, so it is read from a trace of the
+// transaction, which re-executes it in its position in the block.
+func VerifyRevert(ctx context.Context, client *rpc.Client, txHash common.Hash, wantReason string) error {
+	receipt, err := ethclient.NewClient(client).TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusFailed {
+		return ErrNotReverted
+	}
+	call, err := traceCalls(ctx, client, txHash)
+	if err != nil {
+		return err
+	}
+	if call.Error != vm.ErrExecutionReverted.Error() {
+		return fmt.Errorf("transaction failed without revert data: %s", call.Error)
+	}
+	reason, err := abi.UnpackRevert(call.Output)
+	if err != nil {
+		return fmt.Errorf("couldn't decode revert reason: %v", err)
+	}
+	if reason != wantReason {
+		return fmt.Errorf("wrong revert reason (have %q, want %q)", reason, wantReason)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// revertOnceCode sets slot 0 on the first call and reverts with "nope" on every
+// call after it.
+func revertOnceCode() []byte {
+	data := common.FromHex("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6e6f706500000000000000000000000000000000000000000000000000000000")
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 12, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 25, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}
+	return append(code, data...)
+}
+
+func TestVerifyRevert(t *testing.T) {
+	contract := common.Address{0xcc}
+	var txs []*types.Transaction
+	node := newTestNode(t, core.GenesisAlloc{contract: {Code: revertOnceCode()}}, 1, func(i int, b *core.BlockGen) {
+		// The second call only reverts because of the first one in the same
+		// block, so replaying it on the parent state wouldn't.
+		txs = append(txs, sendTx(b, &contract, nil, 100000, nil))
+		txs = append(txs, sendTx(b, &contract, nil, 100000, nil))
+	})
+	ctx := context.Background()
+	if err := VerifyRevert(ctx, node.rpc, txs[1].Hash(), "nope"); err != nil {
+		t.Fatalf("revert not verified: %v", err)
+	}
+	if err := VerifyRevert(ctx, node.rpc, txs[1].Hash(), "other"); err == nil {
+		t.Fatal("wrong reason accepted")
+	}
+	if err := VerifyRevert(ctx, node.rpc, txs[0].Hash(), "nope"); !errors.Is(err, ErrNotReverted) {
+		t.Fatalf("wrong error for successful transaction (have %v, want %v)", err, ErrNotReverted)
+	}
+}
@@ -0,0 +1,32 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VerifyRPCError issues a raw JSON-RPC call and checks that it fails with the
+// given error code and a message containing wantMsgSubstr. An empty substring
+// matches any message.
+func VerifyRPCError(ctx context.Context, client *rpc.Client, method string, params []interface{}, wantCode int, wantMsgSubstr string) error {
+	var result interface{}
+	err := client.CallContext(ctx, &result, method, params...)
+	if err == nil {
+		return fmt.Errorf("call to %s succeeded, want error %d", method, wantCode)
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return fmt.Errorf("call to %s failed without a JSON-RPC error: %v", method, err)
+	}
+	if code := rpcErr.ErrorCode(); code != wantCode {
+		return fmt.Errorf("wrong error code from %s (have %d, want %d): %v", method, code, wantCode, err)
+	}
+	if !strings.Contains(err.Error(), wantMsgSubstr) {
+		return fmt.Errorf("wrong error message from %s (have %q, want it to contain %q)", method, err.Error(), wantMsgSubstr)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VerifySelfDestructed checks that the contract at addr self-destructed.
+//
+// Before EIP-6780 (Cancun), a self-destructed contract has no code left. From
+// Cancun on, SELFDESTRUCT only removes contracts created in the same
+// transaction and otherwise just sends the balance away, so with eip6780 set
+// only the balance is required to be zero. As a zero balance alone proves
+// nothing, txHash is required with eip6780.
+//
+// If txHash is non-zero, addr must have had code at the parent of the
+// transaction's block, and the transaction is traced to confirm it executed
+// SELFDESTRUCT in the context of addr outside of any reverted call. Otherwise
+// addr must have had code at genesis, where challenge contracts are allocated.
+func VerifySelfDestructed(ctx context.Context, client *rpc.Client, addr common.Address, txHash common.Hash, eip6780 bool) error {
+	if eip6780 && txHash == (common.Hash{}) {
+		return errors.New("transaction required to verify self-destruct with EIP-6780")
+	}
+	eth := ethclient.NewClient(client)
+
+	// Make sure there was a contract to destroy in the first place.
+	parent := new(big.Int)
+	if txHash != (common.Hash{}) {
+		receipt, err := eth.TransactionReceipt(ctx, txHash)
+		if err != nil {
+			return fmt.Errorf("couldn't load receipt of %s: %v", txHash, err)
+		}
+		if receipt.BlockNumber.Sign() > 0 {
+			parent.Sub(receipt.BlockNumber, common.Big1)
+		}
+	}
+	code, err := eth.CodeAt(ctx, addr, parent)
+	if err != nil {
+		return fmt.Errorf("couldn't load code at %s: %v", addr, err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no contract at %s in block %d", addr, parent)
+	}
+
+	if eip6780 {
+		balance, err := eth.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			return fmt.Errorf("couldn't load balance of %s: %v", addr, err)
+		}
+		if balance.Sign() != 0 {
+			return fmt.Errorf("contract %s not destroyed, balance is %v", addr, balance)
+		}
+	} else {
+		code, err := eth.CodeAt(ctx, addr, nil)
+		if err != nil {
+			return fmt.Errorf("couldn't load code at %s: %v", addr, err)
+		}
+		if len(code) != 0 {
+			return fmt.Errorf("contract %s not destroyed, code is %d bytes", addr, len(code))
+		}
+	}
+	if txHash == (common.Hash{}) {
+		return nil
+	}
+	call, err := traceCalls(ctx, client, txHash)
+	if err != nil {
+		return err
+	}
+	found := false
+	call.eachSucceeded(func(f *callFrame) {
+		if f.Type == "SELFDESTRUCT" && f.From == addr {
+			found = true
+		}
+	})
+	if !found {
+		return fmt.Errorf("transaction %s didn't self-destruct %s", txHash, addr)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package verify
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+// HeadState opens the state at the head of an in-process chain. Reading state
+// directly avoids RPC round-trips for read-only grading.
+func HeadState(chain *core.BlockChain) (*state.StateDB, error) {
+	head := chain.CurrentBlock()
+	statedb, err := chain.StateAt(head.Root())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open state of block %d: %v", head.NumberU64(), err)
+	}
+	return statedb, nil
+}
+
+// StateBalance returns the balance of addr, or an error if the account doesn't
+// exist.
+func StateBalance(statedb *state.StateDB, addr common.Address) (*big.Int, error) {
+	if !statedb.Exist(addr) {
+		return nil, fmt.Errorf("account %s doesn't exist", addr)
+	}
+	return statedb.GetBalance(addr), nil
+}
+
+// StateStorage returns the value of the storage slot of addr, or an error if the
+// account doesn't exist.
+func StateStorage(statedb *state.StateDB, addr common.Address, slot common.Hash) (common.Hash, error) {
+	if !statedb.Exist(addr) {
+		return common.Hash{}, fmt.Errorf("account %s doesn't exist", addr)
+	}
+	return statedb.GetState(addr, slot), nil
+}
+
+// StateCode returns the code of addr, or an error if the account doesn't exist.
+func StateCode(statedb *state.StateDB, addr common.Address) ([]byte, error) {
+	if !statedb.Exist(addr) {
+		return nil, fmt.Errorf("account %s doesn't exist", addr)
+	}
+	return statedb.GetCode(addr), nil
+}
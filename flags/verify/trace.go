@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VerifyWithTracer traces the transaction with debug_traceTransaction and
+// hands the tracer's result to predicate.
+//
+// The tracer config is passed through verbatim, so it may name a built-in
+// tracer, e.g. {"tracer": "callTracer"}, or carry the source of a JS tracer.
+// A nil config uses the default struct logger.
+func VerifyWithTracer(ctx context.Context, client *rpc.Client, txHash common.Hash, tracerConfig json.RawMessage, predicate func(result json.RawMessage) error) error {
+	var (
+		result json.RawMessage
+		err    error
+	)
+	if tracerConfig == nil {
+		err = client.CallContext(ctx, &result, "debug_traceTransaction", txHash)
+	} else {
+		err = client.CallContext(ctx, &result, "debug_traceTransaction", txHash, tracerConfig)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't trace transaction: %v", err)
+	}
+	return predicate(result)
+}
+
+// callFrame is a call in the output of the built-in callTracer. Nested calls,
+// including SELFDESTRUCTs, are listed in Calls. Output holds the return data,
+// or the revert data of a reverted top-level call.
+type callFrame struct {
+	Type   string         `json:"type"`
+	From   common.Address `json:"from"`
+	To     common.Address `json:"to"`
+	Value  *hexutil.Big   `json:"value"`
+	Output hexutil.Bytes  `json:"output"`
+	Error  string         `json:"error"`
+	Calls  []callFrame    `json:"calls"`
+}
+
+// each calls fn for the frame and all nested frames, depth-first.
+func (f *callFrame) each(fn func(f *callFrame)) {
+	fn(f)
+	for i := range f.Calls {
+		f.Calls[i].each(fn)
+	}
+}
+
+// eachSucceeded is like each, but skips frames that failed along with their
+// nested frames, since their effects were reverted.
+func (f *callFrame) eachSucceeded(fn func(f *callFrame)) {
+	if f.Error != "" {
+		return
+	}
+	fn(f)
+	for i := range f.Calls {
+		f.Calls[i].eachSucceeded(fn)
+	}
+}
+
+// traceCalls traces the transaction with the built-in callTracer.
+func traceCalls(ctx context.Context, client *rpc.Client, txHash common.Hash) (*callFrame, error) {
+	var call callFrame
+	err := VerifyWithTracer(ctx, client, txHash, json.RawMessage(`{"tracer": "callTracer"}`), func(result json.RawMessage) error {
+		return json.Unmarshal(result, &call)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
@@ -0,0 +1,44 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VerifyInternalTransfer checks the total value sent from one address to
+// another by the internal calls of a transaction, i.e. excluding the top-level
+// transfer. Value moved by creations and SELFDESTRUCTs counts, while calls that
+// reverted, along with everything they called, don't.
+func VerifyInternalTransfer(ctx context.Context, client *rpc.Client, txHash common.Hash, from, to common.Address, want *big.Int) error {
+	call, err := traceCalls(ctx, client, txHash)
+	if err != nil {
+		return err
+	}
+	sum := new(big.Int)
+	for i := range call.Calls {
+		sumTransfers(&call.Calls[i], from, to, sum)
+	}
+	if sum.Cmp(want) != 0 {
+		return fmt.Errorf("wrong internal transfers from %s to %s in transaction %s (have %v, want %v)", from, to, txHash, sum, want)
+	}
+	return nil
+}
+
+// sumTransfers adds the value sent from one address to another by the frame
+// and its nested calls to sum.
+func sumTransfers(f *callFrame, from, to common.Address, sum *big.Int) {
+	if f.Error != "" {
+		return
+	}
+	// A DELEGATECALL runs in the caller's context and moves no value.
+	if f.Type != "DELEGATECALL" && f.From == from && f.To == to && f.Value != nil {
+		sum.Add(sum, f.Value.ToInt())
+	}
+	for i := range f.Calls {
+		sumTransfers(&f.Calls[i], from, to, sum)
+	}
+}
@@ -0,0 +1,168 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// VerifyTxIndex checks the position of the transaction within its block.
+func VerifyTxIndex(ctx context.Context, eth *ethclient.Client, txHash common.Hash, wantIndex uint) error {
+	receipt, err := eth.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load receipt: %v", err)
+	}
+	if receipt.TransactionIndex != wantIndex {
+		return fmt.Errorf("wrong index in block %d (have %d, want %d)", receipt.BlockNumber, receipt.TransactionIndex, wantIndex)
+	}
+	return nil
+}
+
+// VerifyEffectiveGasPrice checks the price per unit of gas the transaction paid.
+//
+// The effective gas price isn't part of types.Receipt, so it is read from the
+// raw eth_getTransactionReceipt response.
+func VerifyEffectiveGasPrice(ctx context.Context, client *rpc.Client, txHash common.Hash, want *big.Int) error {
+	var receipt *struct {
+		EffectiveGasPrice *hexutil.Big `json:"effectiveGasPrice"`
+	}
+	if err := client.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return fmt.Errorf("couldn't load receipt: %v", err)
+	}
+	if receipt == nil {
+		return fmt.Errorf("couldn't load receipt: %v", ethereum.NotFound)
+	}
+	if receipt.EffectiveGasPrice == nil {
+		return errors.New("receipt has no effective gas price")
+	}
+	if have := receipt.EffectiveGasPrice.ToInt(); have.Cmp(want) != 0 {
+		return fmt.Errorf("wrong effective gas price (have %v, want %v)", have, want)
+	}
+	return nil
+}
+
+// accessEntry is an address, or a storage slot of an address, in an access list.
+type accessEntry struct {
+	addr   common.Address
+	slot   common.Hash
+	isSlot bool
+}
+
+func (e accessEntry) String() string {
+	if e.isSlot {
+		return fmt.Sprintf("slot %s of %s", e.slot, e.addr)
+	}
+	return fmt.Sprintf("address %s", e.addr)
+}
+
+// accessEntries flattens an access list into the set of its entries.
+func accessEntries(list types.AccessList) map[accessEntry]bool {
+	entries := make(map[accessEntry]bool)
+	for _, tuple := range list {
+		entries[accessEntry{addr: tuple.Address}] = true
+		for _, slot := range tuple.StorageKeys {
+			entries[accessEntry{addr: tuple.Address, slot: slot, isSlot: true}] = true
+		}
+	}
+	return entries
+}
+
+// VerifyAccessList checks the EIP-2930 access list of the transaction. Entries
+// are compared regardless of order, and the error lists the missing and
+// unexpected addresses and storage slots.
+func VerifyAccessList(ctx context.Context, eth *ethclient.Client, txHash common.Hash, want types.AccessList) error {
+	tx, _, err := eth.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load transaction: %v", err)
+	}
+	var (
+		have  = accessEntries(tx.AccessList())
+		wants = accessEntries(want)
+		diff  []string
+	)
+	for entry := range wants {
+		if !have[entry] {
+			diff = append(diff, "missing "+entry.String())
+		}
+	}
+	for entry := range have {
+		if !wants[entry] {
+			diff = append(diff, "unexpected "+entry.String())
+		}
+	}
+	if len(diff) != 0 {
+		sort.Strings(diff)
+		return fmt.Errorf("wrong access list in transaction %s:\n%s", txHash, strings.Join(diff, "\n"))
+	}
+	return nil
+}
+
+// txTypeNames names the transaction types known to this version of geth.
+var txTypeNames = map[uint8]string{
+	types.LegacyTxType:     "legacy",
+	types.AccessListTxType: "access list (EIP-2930)",
+	types.DynamicFeeTxType: "dynamic fee (EIP-1559)",
+}
+
+// txTypeName returns a readable name for the transaction type.
+func txTypeName(typ uint8) string {
+	if name, ok := txTypeNames[typ]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown type %d", typ)
+}
+
+// VerifyTxType checks the type of the transaction, e.g. types.DynamicFeeTxType
+// to require an EIP-1559 transaction.
+func VerifyTxType(ctx context.Context, eth *ethclient.Client, txHash common.Hash, wantType uint8) error {
+	tx, _, err := eth.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load transaction: %v", err)
+	}
+	if tx.Type() != wantType {
+		return fmt.Errorf("wrong transaction type (have %s, want %s)", txTypeName(tx.Type()), txTypeName(wantType))
+	}
+	return nil
+}
+
+// VerifyCalldataSize checks that the transaction's calldata is at most maxBytes
+// long.
+func VerifyCalldataSize(ctx context.Context, eth *ethclient.Client, txHash common.Hash, maxBytes int) error {
+	tx, _, err := eth.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("couldn't load transaction: %v", err)
+	}
+	if size := len(tx.Data()); size > maxBytes {
+		return fmt.Errorf("calldata too large (have %d bytes, max %d)", size, maxBytes)
+	}
+	return nil
+}
+
+// VerifySenderForTxs checks that every transaction was sent by want. Senders are
+// recovered from the signatures rather than taken from the client's response.
+func VerifySenderForTxs(ctx context.Context, eth *ethclient.Client, txHashes []common.Hash, want common.Address) error {
+	for _, hash := range txHashes {
+		tx, _, err := eth.TransactionByHash(ctx, hash)
+		if err != nil {
+			return fmt.Errorf("couldn't load transaction %s: %v", hash, err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return fmt.Errorf("couldn't recover sender of %s: %v", hash, err)
+		}
+		if sender != want {
+			return fmt.Errorf("wrong sender of transaction %s (have %s, want %s)", hash, sender, want)
+		}
+	}
+	return nil
+}
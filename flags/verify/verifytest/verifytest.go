@@ -0,0 +1,129 @@
+// Package verifytest provides fluent state assertions for Go tests. It lives
+// apart from package verify so the checkers don't link the testing package.
+package verifytest
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Tester wraps the verification primitives for use in Go tests. Every assertion
+// fails the test immediately on mismatch or on an RPC error.
+//
+//	v := verifytest.T(t, eth)
+//	v.Balance(addr).Equals(big.NewInt(1))
+//	v.Code(addr).Exists()
+type Tester struct {
+	t   testing.TB
+	eth *ethclient.Client
+}
+
+// T creates a Tester reading state from eth at the latest block.
+func T(t testing.TB, eth *ethclient.Client) *Tester {
+	return &Tester{t: t, eth: eth}
+}
+
+// Balance returns an assertion on the balance of addr.
+func (v *Tester) Balance(addr common.Address) *BalanceAssertion {
+	v.t.Helper()
+	balance, err := v.eth.BalanceAt(context.Background(), addr, nil)
+	if err != nil {
+		v.t.Fatalf("couldn't load balance of %s: %v", addr, err)
+	}
+	return &BalanceAssertion{t: v.t, addr: addr, have: balance}
+}
+
+// Storage returns an assertion on the value of a storage slot of addr.
+func (v *Tester) Storage(addr common.Address, slot common.Hash) *StorageAssertion {
+	v.t.Helper()
+	value, err := v.eth.StorageAt(context.Background(), addr, slot, nil)
+	if err != nil {
+		v.t.Fatalf("couldn't load storage %s of %s: %v", slot, addr, err)
+	}
+	return &StorageAssertion{t: v.t, addr: addr, slot: slot, have: common.BytesToHash(value)}
+}
+
+// Code returns an assertion on the code of addr.
+func (v *Tester) Code(addr common.Address) *CodeAssertion {
+	v.t.Helper()
+	code, err := v.eth.CodeAt(context.Background(), addr, nil)
+	if err != nil {
+		v.t.Fatalf("couldn't load code of %s: %v", addr, err)
+	}
+	return &CodeAssertion{t: v.t, addr: addr, have: code}
+}
+
+// BalanceAssertion asserts properties of an account balance.
+type BalanceAssertion struct {
+	t    testing.TB
+	addr common.Address
+	have *big.Int
+}
+
+// Equals fails the test unless the balance equals want.
+func (a *BalanceAssertion) Equals(want *big.Int) {
+	a.t.Helper()
+	if a.have.Cmp(want) != 0 {
+		a.t.Fatalf("wrong balance for %s (have %v, want %v)", a.addr, a.have, want)
+	}
+}
+
+// AtLeast fails the test if the balance is below min.
+func (a *BalanceAssertion) AtLeast(min *big.Int) {
+	a.t.Helper()
+	if a.have.Cmp(min) < 0 {
+		a.t.Fatalf("balance of %s too low (have %v, want at least %v)", a.addr, a.have, min)
+	}
+}
+
+// StorageAssertion asserts properties of a storage slot.
+type StorageAssertion struct {
+	t    testing.TB
+	addr common.Address
+	slot common.Hash
+	have common.Hash
+}
+
+// Equals fails the test unless the slot holds want.
+func (a *StorageAssertion) Equals(want common.Hash) {
+	a.t.Helper()
+	if a.have != want {
+		a.t.Fatalf("wrong value in slot %s of %s (have %s, want %s)", a.slot, a.addr, a.have, want)
+	}
+}
+
+// CodeAssertion asserts properties of an account's code.
+type CodeAssertion struct {
+	t    testing.TB
+	addr common.Address
+	have []byte
+}
+
+// Exists fails the test if the account has no code.
+func (a *CodeAssertion) Exists() {
+	a.t.Helper()
+	if len(a.have) == 0 {
+		a.t.Fatalf("no code at %s", a.addr)
+	}
+}
+
+// Empty fails the test if the account has code.
+func (a *CodeAssertion) Empty() {
+	a.t.Helper()
+	if len(a.have) != 0 {
+		a.t.Fatalf("unexpected code at %s (%d bytes)", a.addr, len(a.have))
+	}
+}
+
+// Equals fails the test unless the code matches want exactly.
+func (a *CodeAssertion) Equals(want []byte) {
+	a.t.Helper()
+	if !bytes.Equal(a.have, want) {
+		a.t.Fatalf("wrong code at %s (have %x, want %x)", a.addr, a.have, want)
+	}
+}
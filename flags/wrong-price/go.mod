@@ -2,10 +2,14 @@ module wrong-price
 
 go 1.19
 
-replace github.com/ethereum/go-ethereum v1.10.23 => ./go-ethereum
+replace (
+	github.com/ethereum/go-ethereum v1.10.23 => ./go-ethereum
+	github.com/lightclient/protocol-ctf => ../..
+)
 
 require (
 	github.com/ethereum/go-ethereum v1.10.23
+	github.com/lightclient/protocol-ctf v0.0.0
 	github.com/urfave/cli/v2 v2.14.1
 )
 
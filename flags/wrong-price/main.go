@@ -1,13 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"strings"
 
@@ -16,15 +19,20 @@ import (
 	"github.com/ethereum/go-ethereum/console"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native" // Registers callTracer and the other built-in tracers
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/lightclient/protocol-ctf/flags/verify"
 )
 
 func main() {
@@ -32,6 +40,12 @@ func main() {
 		logLevelStr = flag.String("loglevel", "error", "Log level")
 		quiet       = flag.Bool("quiet", false, "Don't print any client logs")
 		consoleMode = flag.Bool("console", false, "Leaves client open after flag check")
+		stateMode   = flag.Bool("state", false, "Checks the imported chain directly instead of over RPC")
+		p2pMode     = flag.Bool("p2p", false, "Lets external peers discover and connect to the client (use with --console)")
+		p2pAddr     = flag.String("p2p.addr", "127.0.0.1:0", "P2P listen address used with --p2p")
+		nodeKeyHex  = flag.String("p2p.nodekey", "", "Hex encoded P2P node key, for a stable enode across runs (random by default)")
+		maxPeers    = flag.Int("p2p.maxpeers", 0, "Maximum number of peers used with --p2p (geth's default when zero)")
+		netRestrict = flag.String("p2p.netrestrict", "", "Comma separated CIDR masks peers are restricted to with --p2p")
 	)
 	flag.Parse()
 
@@ -41,7 +55,29 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := checkFlag(lvl, *quiet, *consoleMode); err != nil {
+	opts := &nodeOptions{
+		apis:       challengeAPIs,
+		p2p:        *p2pMode,
+		listenAddr: *p2pAddr,
+		maxPeers:   *maxPeers,
+	}
+	if *nodeKeyHex != "" {
+		if opts.nodeKey, err = crypto.HexToECDSA(strings.TrimPrefix(*nodeKeyHex, "0x")); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid node key: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if *maxPeers < 0 {
+		fmt.Fprintf(os.Stderr, "invalid maximum number of peers: %d\n", *maxPeers)
+		os.Exit(1)
+	}
+	if *netRestrict != "" {
+		if opts.netRestrict, err = netutil.ParseNetlist(*netRestrict); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid netrestrict: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := checkFlag(lvl, *quiet, *consoleMode, *stateMode, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Flag not captured: %s\n", err)
 		os.Exit(1)
 	}
@@ -49,7 +85,39 @@ func main() {
 	fmt.Println("Flag captured.")
 }
 
-func checkFlag(logLevel log.Lvl, quiet, consoleMode bool) error {
+var (
+	// genesisHash is the hash of the challenge's genesis block.
+	genesisHash = common.HexToHash("0x1f1821dd44ed12bbb05b1cfeaf4a17a74f0f3f3dbd677f23ef93832dcb9b270d")
+
+	// flagBlocks are the canonical blocks a solved chain contains. Challenges
+	// solved across several blocks list each of them.
+	flagBlocks = []flagBlock{
+		{1, common.HexToHash("0x31553f1bb856b900a24d456f51ac4372fa57e08c5a16812db3ff87e63320bf26")},
+	}
+
+	// flagAccounts are the accounts of a solved chain's head state checked by
+	// the state mode.
+	flagAccounts = []flagAccount{
+		{address: common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7"), balance: big.NewInt(981617125000000)},
+		{address: common.Address{0xaa}, balance: new(big.Int), code: common.FromHex("0x6041600101")},
+	}
+)
+
+// flagBlock is a block expected in the canonical chain.
+type flagBlock struct {
+	number uint64
+	hash   common.Hash
+}
+
+// flagAccount is the expected state of an account. Nil fields aren't checked.
+type flagAccount struct {
+	address common.Address
+	balance *big.Int
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+func checkFlag(logLevel log.Lvl, quiet, consoleMode, stateMode bool, opts *nodeOptions) error {
 	w := (io.Writer)(os.Stderr)
 	if quiet {
 		w = ioutil.Discard
@@ -59,7 +127,7 @@ func checkFlag(logLevel log.Lvl, quiet, consoleMode bool) error {
 	log.Root().SetHandler(glogger)
 
 	// Start geth.
-	node, err := runGeth()
+	node, backend, err := runGeth(opts)
 	if err != nil {
 		return err
 	}
@@ -73,42 +141,155 @@ func checkFlag(logLevel log.Lvl, quiet, consoleMode bool) error {
 		fmt.Println()
 	}
 
+	if stateMode {
+		return checkState(backend.BlockChain())
+	}
+
 	rpc, err := node.Attach()
 	if err != nil {
 		return nil
 	}
 	eth := ethclient.NewClient(rpc)
 
-	// Verify flag.
+	// Make sure the intended genesis was loaded before verifying the flag.
 	ctx := context.Background()
-	block, err := eth.BlockByNumber(ctx, common.Big1)
+	if err := verify.VerifyGenesis(ctx, eth, genesisHash); err != nil {
+		return err
+	}
+
+	// Verify flag.
+	for _, want := range flagBlocks {
+		header, err := eth.HeaderByNumber(ctx, new(big.Int).SetUint64(want.number))
+		if err != nil {
+			return fmt.Errorf("couldn't load block %d", want.number)
+		}
+		if header.Hash() != want.hash {
+			return fmt.Errorf("could not load chain, block %d doesn't match", want.number)
+		}
+	}
+
+	return nil
+}
+
+// checkState verifies the flag against the in-process chain, avoiding RPC
+// round-trips entirely. Besides the flag blocks, it checks the head state of
+// the flag accounts.
+func checkState(chain *core.BlockChain) error {
+	if have := chain.Genesis().Hash(); have != genesisHash {
+		return fmt.Errorf("wrong genesis (have %s, want %s)", have, genesisHash)
+	}
+	for _, want := range flagBlocks {
+		header := chain.GetHeaderByNumber(want.number)
+		if header == nil {
+			return fmt.Errorf("couldn't load block %d", want.number)
+		}
+		if header.Hash() != want.hash {
+			return fmt.Errorf("could not load chain, block %d doesn't match", want.number)
+		}
+	}
+	statedb, err := verify.HeadState(chain)
 	if err != nil {
-		return fmt.Errorf("couldn't load head block")
+		return err
 	}
-	if block.Hash() != common.HexToHash("0x31553f1bb856b900a24d456f51ac4372fa57e08c5a16812db3ff87e63320bf26") {
-		return fmt.Errorf("could not load chain")
+	for _, want := range flagAccounts {
+		if want.balance != nil {
+			balance, err := verify.StateBalance(statedb, want.address)
+			if err != nil {
+				return err
+			}
+			if balance.Cmp(want.balance) != 0 {
+				return fmt.Errorf("wrong balance of %s (have %v, want %v)", want.address, balance, want.balance)
+			}
+		}
+		if want.code != nil {
+			code, err := verify.StateCode(statedb, want.address)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(code, want.code) {
+				return fmt.Errorf("wrong code at %s (have %x, want %x)", want.address, code, want.code)
+			}
+		}
+		for slot, value := range want.storage {
+			have, err := verify.StateStorage(statedb, want.address, slot)
+			if err != nil {
+				return err
+			}
+			if have != value {
+				return fmt.Errorf("wrong storage of %s at %s (have %s, want %s)", want.address, slot, have, value)
+			}
+		}
 	}
+	return nil
+}
 
+// challengeAPIs returns the challenge specific RPC APIs to expose on the node,
+// in addition to the standard and debug_trace* ones.
+//
+// To offer solvers helper methods, implement them on a service type and list
+// it here. Exported methods are served as <namespace>_<method>, e.g.
+//
+//	type ctfAPI struct{ backend *eth.Ethereum }
+//
+//	// Head is served as ctf_head.
+//	func (api *ctfAPI) Head() uint64 {
+//		return api.backend.BlockChain().CurrentBlock().NumberU64()
+//	}
+//
+//	return []rpc.API{{Namespace: "ctf", Service: &ctfAPI{backend}}}
+func challengeAPIs(backend *eth.Ethereum) []rpc.API {
 	return nil
 }
 
-// runGeth creates and starts a geth node
-func runGeth() (*node.Node, error) {
+// nodeOptions configures the node started by runGeth.
+type nodeOptions struct {
+	apis func(backend *eth.Ethereum) []rpc.API // Challenge specific RPC APIs
+
+	// By default the node is isolated from the network. With p2p set, it
+	// listens on listenAddr and takes part in discovery and dialing, so a
+	// solver's node can peer with it. Connections are limited to maxPeers,
+	// geth's default when zero, from the networks in netRestrict if set.
+	p2p         bool
+	listenAddr  string
+	maxPeers    int
+	netRestrict *netutil.Netlist
+
+	nodeKey *ecdsa.PrivateKey // Node identity, random if nil
+}
+
+// runGeth creates and starts a geth node. Address preimages are recorded, so
+// the accounts of its state can be listed with debug_accountRange.
+func runGeth(opts *nodeOptions) (*node.Node, *eth.Ethereum, error) {
+	config := p2p.Config{
+		ListenAddr:  "127.0.0.1:0",
+		NoDiscovery: true,
+		NoDial:      true,
+	}
+	if opts.p2p {
+		config = p2p.Config{
+			ListenAddr:  opts.listenAddr,
+			MaxPeers:    opts.maxPeers,
+			NetRestrict: opts.netRestrict,
+		}
+		if config.MaxPeers == 0 {
+			config.MaxPeers = node.DefaultConfig.P2P.MaxPeers
+		}
+	}
+	config.PrivateKey = opts.nodeKey
+	// Identify as geth over RPC, so graders can gate on web3_clientVersion.
 	stack, err := node.New(&node.Config{
-		P2P: p2p.Config{
-			ListenAddr:  "127.0.0.1:0",
-			NoDiscovery: true,
-			NoDial:      true,
-		},
+		Name:    "Geth",
+		Version: params.VersionWithMeta,
+		P2P:     config,
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	chain, err := loadChain("chain.rlp", "genesis.json")
 	if err != nil {
 		stack.Close()
-		return nil, err
+		return nil, nil, err
 	}
 	backend, err := eth.New(stack, &ethconfig.Config{
 		Genesis:   &chain.genesis,
@@ -116,12 +297,16 @@ func runGeth() (*node.Node, error) {
 		Ethash: ethash.Config{
 			PowMode: ethash.ModeFake,
 		},
+		Preimages: true,
 	})
 	if err != nil {
 		stack.Close()
-		return nil, err
+		return nil, nil, err
 	}
 	stack.RegisterAPIs(tracers.APIs(tracers.Backend(backend.APIBackend)))
+	if opts.apis != nil {
+		stack.RegisterAPIs(opts.apis(backend))
+	}
 
 	_, err = backend.BlockChain().InsertChain(chain.blocks[1:])
 	if err != nil {
@@ -130,9 +315,12 @@ func runGeth() (*node.Node, error) {
 
 	if err = stack.Start(); err != nil {
 		stack.Close()
-		return nil, err
+		return nil, nil, err
+	}
+	if opts.p2p {
+		fmt.Printf("Client reachable at %s\n", stack.Server().Self().URLv4())
 	}
-	return stack, nil
+	return stack, backend, nil
 }
 
 type Chain struct {
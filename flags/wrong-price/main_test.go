@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/lightclient/protocol-ctf/flags/verify"
+)
+
+// TestTraceCalls checks the checker's node serves the built-in callTracer, which
+// the trace based verify helpers depend on.
+func TestTraceCalls(t *testing.T) {
+	stack, backend, err := runGeth(&nodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stack.Close()
+
+	// The unsolved chain doesn't import past genesis, so add a block of our own
+	// with a transfer to trace.
+	chain, err := loadChain("chain.rlp", "genesis.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		from   = crypto.PubkeyToAddress(key.PublicKey)
+		to     = common.Address{0xbb}
+		db     = rawdb.NewMemoryDatabase()
+		signer = types.LatestSigner(chain.genesis.Config)
+		tx     *types.Transaction
+	)
+	genesis := chain.genesis.MustCommit(db)
+	blocks, _ := core.GenerateChain(chain.genesis.Config, genesis, ethash.NewFaker(), db, 1, func(i int, b *core.BlockGen) {
+		tx = types.MustSignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    b.TxNonce(from),
+			To:       &to,
+			Value:    big.NewInt(1),
+			Gas:      21000,
+			GasPrice: b.BaseFee(),
+		})
+		b.AddTx(tx)
+	})
+	if _, err := backend.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("couldn't import block: %v", err)
+	}
+
+	client, err := stack.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	err = verify.VerifyWithTracer(ctx, client, tx.Hash(), json.RawMessage(`{"tracer": "callTracer"}`), func(result json.RawMessage) error {
+		var call struct {
+			Type string         `json:"type"`
+			To   common.Address `json:"to"`
+		}
+		if err := json.Unmarshal(result, &call); err != nil {
+			return err
+		}
+		if call.Type != "CALL" || call.To != to {
+			return fmt.Errorf("wrong top-level call (have %s to %s, want CALL to %s)", call.Type, call.To, to)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The top-level transfer isn't internal, so none are found.
+	if err := verify.VerifyInternalTransfer(ctx, client, tx.Hash(), from, to, new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNodeOptions checks the P2P options reach the node's server.
+func TestNodeOptions(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	restrict, err := netutil.ParseNetlist("127.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		opts         *nodeOptions
+		wantMaxPeers int
+	}{
+		{opts: &nodeOptions{p2p: true, listenAddr: "127.0.0.1:0"}, wantMaxPeers: node.DefaultConfig.P2P.MaxPeers},
+		{opts: &nodeOptions{p2p: true, listenAddr: "127.0.0.1:0", maxPeers: 2, netRestrict: restrict, nodeKey: key}, wantMaxPeers: 2},
+	} {
+		stack, _, err := runGeth(tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := stack.Server()
+		if server.MaxPeers != tt.wantMaxPeers {
+			t.Errorf("wrong max peers (have %d, want %d)", server.MaxPeers, tt.wantMaxPeers)
+		}
+		if server.NetRestrict != tt.opts.netRestrict {
+			t.Errorf("wrong netrestrict (have %v, want %v)", server.NetRestrict, tt.opts.netRestrict)
+		}
+		if tt.opts.nodeKey != nil {
+			if have, want := server.Self().ID(), enode.PubkeyToIDV4(&key.PublicKey); have != want {
+				t.Errorf("wrong node ID (have %s, want %s)", have, want)
+			}
+		}
+		stack.Close()
+	}
+}
+
+// TestClientVersion checks the node identifies as geth.
+func TestClientVersion(t *testing.T) {
+	stack, _, err := runGeth(&nodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stack.Close()
+	client, err := stack.Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := verify.ClientVersion(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(version, "Geth") {
+		t.Errorf("unexpected client version %q, want Geth", version)
+	}
+}